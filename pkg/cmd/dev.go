@@ -0,0 +1,395 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubectl/pkg/util/term"
+	"sigs.k8s.io/controller-runtime/pkg/manager/signals"
+
+	"github.com/feloy/ododev/pkg/controller"
+	"github.com/feloy/ododev/pkg/devfile"
+	"github.com/feloy/ododev/pkg/filesystem"
+	"github.com/feloy/ododev/pkg/libdevfile"
+	"github.com/feloy/ododev/pkg/sync"
+
+	bindingApi "github.com/redhat-developer/service-binding-operator/apis/binding/v1alpha1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+const (
+	restartPolicyFlag = "restart-policy"
+	debugFlag         = "debug"
+	logsFlag          = "logs"
+)
+
+func NewDevCommand() *cobra.Command {
+	var (
+		o             Options
+		vo            VariablesOptions
+		po            PortOptions
+		restartPolicy string
+		debug         bool
+		logs          bool
+	)
+	devCmd := &cobra.Command{
+		Use:   "dev",
+		Short: "Deploy the component to the cluster and synchronize the sources while they are modified",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			policy, err := devfile.ParseRestartPolicy(restartPolicy)
+			if err != nil {
+				return err
+			}
+			err = vo.Complete()
+			if err != nil {
+				return err
+			}
+			err = o.Complete(vo.Variables)
+			if err != nil {
+				return err
+			}
+			err = po.Complete()
+			if err != nil {
+				return err
+			}
+			return runDev(o, vo.Variables, po.Pinned, policy, debug, logs)
+		},
+	}
+	o.AddFlags(devCmd.Flags())
+	vo.AddFlags(devCmd.Flags())
+	po.AddFlags(devCmd.Flags())
+	devCmd.Flags().StringVar(&restartPolicy, restartPolicyFlag, string(devfile.DefaultRestartPolicy), "When to restart the run command after it exited: always, on-failure or never")
+	devCmd.Flags().BoolVar(&debug, debugFlag, false, "Execute the default debug command instead of the default run command, and forward the debug port")
+	devCmd.Flags().BoolVar(&logs, logsFlag, true, "Display the logs of the containers of the component")
+	return devCmd
+}
+
+func runDev(o Options, variables map[string]string, pinnedPorts map[string]int, restartPolicy devfile.RestartPolicy, debug bool, logs bool) error {
+	completeTarFile := filepath.Join(o.DotOdoDirectory, "complete.tar")
+	diffTarFile := filepath.Join(o.DotOdoDirectory, "diff.tar")
+	// syncedManifestFile contains the manifest of the sources sent to the container,
+	// used to detect the files deleted between two sessions
+	syncedManifestFile := filepath.Join(o.DotOdoDirectory, "synced-manifest.json")
+
+	devfileObj, err := devfile.ParseDevfile(o.DevfilePath, variables)
+	if err != nil {
+		return err
+	}
+	err = libdevfile.CheckPinnedPorts(devfileObj, pinnedPorts, debug)
+	if err != nil {
+		return err
+	}
+
+	// Check .odo exists
+	err = os.Mkdir(o.DotOdoDirectory, 0755)
+	if err != nil {
+		if !os.IsExist(err) {
+			return err
+		}
+	}
+
+	f, err := os.Create(filepath.Join(o.DotOdoDirectory, "controller.log"))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	log.SetLogger(zap.New(zap.WriteTo(f)))
+
+	entryLog := log.Log.WithName("entrypoint")
+
+	mgr, err := manager.New(o.RestConfig, manager.Options{
+		Namespace: o.Namespace,
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx := signals.SetupSignalHandler()
+
+	// the archives are published with a client not using the cache of the manager,
+	// as they are published before the manager is started
+	blobClient, err := client.New(o.RestConfig, client.Options{Scheme: mgr.GetScheme()})
+	if err != nil {
+		return err
+	}
+
+	// register ServiceBinding resources, before the controller watches them
+	mgr.GetClient().Scheme().AddKnownTypes(bindingApi.GroupVersion, &bindingApi.ServiceBinding{}, &bindingApi.ServiceBindingList{})
+	metav1.AddToGroupVersion(mgr.GetClient().Scheme(), bindingApi.GroupVersion)
+
+	go func() {
+		entryLog.Info("starting manager")
+		err := controller.StartManager(ctx, mgr, o.Namespace, o.ComponentName)
+		if err != nil {
+			panic(err)
+		}
+	}()
+
+	ignoreMatcher, err := filesystem.GetIgnoreMatcher(o.WorkingDir)
+	if err != nil {
+		return err
+	}
+
+	// the local files of ododev are not synchronized when they are stored inside the sources
+	odoDir, err := filesystem.RelativeDir(o.WorkingDir, o.DotOdoDirectory)
+	if err != nil {
+		return err
+	}
+
+	files, err := filesystem.ListFiles(o.WorkingDir, ignoreMatcher, odoDir)
+	if err != nil {
+		return err
+	}
+
+	manifest, err := filesystem.ArchiveFiles(o.WorkingDir, completeTarFile, files)
+	if err != nil {
+		return err
+	}
+	completeGeneration := manifest.Digest()
+	err = devfile.DeleteBlobs(ctx, blobClient, o.Namespace, o.ComponentName)
+	if err != nil {
+		return err
+	}
+	completeArchive, err := publishArchive(ctx, blobClient, o, "complete", completeTarFile)
+	if err != nil {
+		return err
+	}
+	completeManifest, err := publishManifest(ctx, blobClient, o, manifest)
+	if err != nil {
+		return err
+	}
+
+	// files modified and deleted since the complete archive has been created,
+	// starting with the files deleted since the previous session
+	changes := filesystem.NewChanges(o.WorkingDir)
+	previousManifest, err := filesystem.ReadManifest(syncedManifestFile)
+	if err != nil {
+		return err
+	}
+	_, deletedSinceLastSession := filesystem.Diff(previousManifest, manifest)
+	changes.Add(fromSlash(deletedSinceLastSession), nil)
+	err = filesystem.WriteManifest(syncedManifestFile, manifest)
+	if err != nil {
+		return err
+	}
+
+	previousPorts, err := readPorts(o.DotOdoDirectory, o.ComponentName)
+	if err != nil {
+		return err
+	}
+
+	// the spec and the status can be left over from a previous session, the generation must be greater
+	// than the ones they contain so the first spec of the session is not considered as already observed
+	lastGeneration, err := devfile.GetLastGeneration(ctx, blobClient, o.Namespace, o.ComponentName)
+	if err != nil {
+		return err
+	}
+
+	cmContent := devfile.ConfigMapContent{
+		Generation:             lastGeneration + 1,
+		Devfile:                o.DevfilePath,
+		CompleteSyncGeneration: completeGeneration,
+		CompleteArchive:        completeArchive,
+		Manifests: map[string]devfile.BlobRef{
+			completeGeneration: *completeManifest,
+		},
+		DeletedFiles:  toSlash(changes.Deleted()),
+		Variables:     variables,
+		RestartPolicy: restartPolicy,
+		Debug:         debug,
+		PinnedPorts:   pinnedPorts,
+		PreviousPorts: previousPorts,
+	}
+	devfileConfigMap, err := devfile.CreateConfigMapFromDevfile(ctx, mgr.GetClient(), o.Namespace, o.ComponentName, cmContent)
+	if err != nil {
+		return err
+	}
+
+	statusWatcher, err := devfile.WatchStatus(ctx, mgr.GetClient(), mgr, o.Namespace, o.ComponentName)
+	if err != nil {
+		return err
+	}
+
+	if logs {
+		go func() {
+			err := controller.FollowLogs(ctx, mgr, o.Namespace, o.ComponentName, controller.LogsOptions{
+				Follow: true,
+				Color:  term.AllowsColorOutput(os.Stdout),
+			}, os.Stdout)
+			if err != nil {
+				fmt.Printf("error following logs: %s\n", err)
+			}
+		}()
+	}
+
+	// forwardedPorts are the last forwarded ports displayed to the user
+	var forwardedPorts []devfile.ForwardedPort
+	err = sync.Watch(ctx, o.DevfilePath, o.WorkingDir, ignoreMatcher, odoDir, statusWatcher,
+		func(status devfile.StatusContent) {
+			printStatus(status)
+			if len(status.ForwardedPorts) == 0 || reflect.DeepEqual(status.ForwardedPorts, forwardedPorts) {
+				return
+			}
+			forwardedPorts = status.ForwardedPorts
+			for _, port := range forwardedPorts {
+				fmt.Printf("forwarding endpoint %q of container %q: localhost:%d -> %d (%s)\n", port.EndpointName, port.ContainerName, port.LocalPort, port.ContainerPort, port.State)
+				if port.Error != "" {
+					fmt.Printf("  %s\n", port.Error)
+				}
+			}
+			err := writePorts(o.DotOdoDirectory, o.ComponentName, forwardedPorts)
+			if err != nil {
+				fmt.Printf("error saving the forwarded ports: %s\n", err)
+			}
+		},
+		func() error {
+			cmContent.Generation++
+			_, err := devfile.CreateConfigMapFromDevfile(ctx, mgr.GetClient(), o.Namespace, o.ComponentName, cmContent)
+			return err
+		}, func(deleted []string, modified []string) error {
+			if len(modified) > 0 {
+				fmt.Printf("Files modified: %s\n", strings.Join(modified, ", "))
+			}
+			if len(deleted) > 0 {
+				fmt.Printf("Files deleted: %s\n", strings.Join(deleted, ", "))
+			}
+			changes.Add(deleted, modified)
+			_, err := filesystem.ArchiveFiles(o.WorkingDir, diffTarFile, changes.Modified())
+			if err != nil {
+				return err
+			}
+			current, err := filesystem.NewManifest(o.WorkingDir, changes.Apply(files))
+			if err != nil {
+				return err
+			}
+			generation := current.Digest()
+			incrementalArchive, err := publishArchive(ctx, blobClient, o, "diff", diffTarFile)
+			if err != nil {
+				return err
+			}
+			currentManifest, err := publishManifest(ctx, blobClient, o, current)
+			if err != nil {
+				return err
+			}
+			// the manifest of the previous generation is kept, as it can be the last one synced by the controller
+			manifests := map[string]devfile.BlobRef{
+				completeGeneration: *completeManifest,
+				generation:         *currentManifest,
+			}
+			keep := []string{"complete", "diff", completeManifest.Name, currentManifest.Name}
+			if previous, ok := cmContent.Manifests[cmContent.IncrementalSyncGeneration]; ok {
+				manifests[cmContent.IncrementalSyncGeneration] = previous
+				keep = append(keep, previous.Name)
+			}
+			err = devfile.DeleteBlobs(ctx, blobClient, o.Namespace, o.ComponentName, keep...)
+			if err != nil {
+				return err
+			}
+			err = filesystem.WriteManifest(syncedManifestFile, current)
+			if err != nil {
+				return err
+			}
+			cmContent.IncrementalSyncGeneration = generation
+			cmContent.IncrementalArchive = incrementalArchive
+			cmContent.Manifests = manifests
+			cmContent.DeletedFiles = toSlash(changes.Deleted())
+			cmContent.Generation++
+			_, err = devfile.CreateConfigMapFromDevfile(ctx, mgr.GetClient(), o.Namespace, o.ComponentName, cmContent)
+			return err
+		})
+	if err != nil {
+		fmt.Printf("error watching files: %s\n", err)
+	}
+
+	fmt.Println("Cleanup resources, please wait or press Ctrl-c again to not wait resource cleanup is done")
+	// use a new context as the previous has been canceled
+	cleanupCtx := context.Background()
+	// the devfile can have been modified during the session
+	devfileObj, err = devfile.ParseDevfile(o.DevfilePath, variables)
+	if err == nil {
+		err = controller.ExecStopEvents(cleanupCtx, mgr, o.Namespace, o.ComponentName, devfileObj, os.Stdout)
+	}
+	if err != nil {
+		fmt.Printf("error executing preStop and postStop commands: %s\n", err)
+	}
+	err = devfile.DeleteConfigMapAndWait(cleanupCtx, mgr, mgr.GetClient(), devfileConfigMap)
+	if err != nil {
+		return err
+	}
+	return devfile.DeleteBlobs(cleanupCtx, blobClient, o.Namespace, o.ComponentName)
+}
+
+// publishArchive publishes the content of an archive of the sources into the cluster
+func publishArchive(ctx context.Context, cli client.Client, o Options, name string, tarFile string) (*devfile.BlobRef, error) {
+	data, err := os.ReadFile(tarFile)
+	if err != nil {
+		return nil, err
+	}
+	return devfile.PublishBlob(ctx, cli, o.Namespace, o.ComponentName, name, data)
+}
+
+// publishManifest publishes a manifest into the cluster, for the controller to compute the files changed between two generations.
+// The blob is named after the generation, so the manifests of several generations can be published at the same time
+func publishManifest(ctx context.Context, cli client.Client, o Options, manifest filesystem.Manifest) (*devfile.BlobRef, error) {
+	data, err := filesystem.EncodeManifest(manifest)
+	if err != nil {
+		return nil, err
+	}
+	name := "manifest-" + strings.TrimPrefix(manifest.Digest(), "sha256:")[:12]
+	return devfile.PublishBlob(ctx, cli, o.Namespace, o.ComponentName, name, data)
+}
+
+// fromSlash converts paths in the container to local paths
+func fromSlash(files []string) []string {
+	result := make([]string, 0, len(files))
+	for _, file := range files {
+		result = append(result, filepath.FromSlash(file))
+	}
+	return result
+}
+
+// toSlash converts local paths to paths in the container
+func toSlash(files []string) []string {
+	result := make([]string, 0, len(files))
+	for _, file := range files {
+		result = append(result, filepath.ToSlash(file))
+	}
+	return result
+}
+
+// printStatus displays the status of the component, and the result of the last command
+// when the build failed or the run command exited
+func printStatus(status devfile.StatusContent) {
+	fmt.Printf("new status: %s\n", status.Status)
+	for _, sub := range status.SubCommands {
+		fmt.Printf("  command %q: %s\n", sub.Command, sub.State)
+	}
+	if status.LastCommand == nil {
+		return
+	}
+	switch status.Status {
+	case devfile.StatusBuildFailed, devfile.StatusRunCommandExited, devfile.StatusRunCommandBackOff:
+		cmd := status.LastCommand
+		fmt.Printf("command %q exited with code %d (started at %s, finished at %s)\n",
+			cmd.Command, cmd.ExitCode, cmd.StartedAt.Format(time.RFC3339), cmd.FinishedAt.Format(time.RFC3339))
+		if cmd.Output != "" {
+			fmt.Println(cmd.Output)
+		}
+		if status.Status == devfile.StatusRunCommandBackOff && status.RestartCount != nil {
+			fmt.Printf("restarting command %q (restarted %d times)\n", cmd.Command, *status.RestartCount)
+		}
+	}
+}
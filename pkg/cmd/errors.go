@@ -0,0 +1,15 @@
+package cmd
+
+// ExitCodeError is returned by a command which must exit with a specific exit code
+type ExitCodeError struct {
+	Code int
+	Err  error
+}
+
+func (e ExitCodeError) Error() string {
+	return e.Err.Error()
+}
+
+func (e ExitCodeError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	utilexec "k8s.io/client-go/util/exec"
+	"k8s.io/kubectl/pkg/util/term"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/manager/signals"
+
+	"github.com/feloy/ododev/pkg/controller"
+	"github.com/feloy/ododev/pkg/devfile"
+)
+
+func NewExecCommand() *cobra.Command {
+	var (
+		o             Options
+		vo            VariablesOptions
+		containerName string
+	)
+	execCmd := &cobra.Command{
+		Use:   "exec [--container name] -- command [args...]",
+		Short: "Execute a command in a container of the component deployed by ododev dev, from the directory of the sources",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := vo.Complete()
+			if err != nil {
+				return err
+			}
+			err = o.Complete(vo.Variables)
+			if err != nil {
+				return err
+			}
+			return runExec(o, containerName, args)
+		},
+	}
+	o.AddFlags(execCmd.Flags())
+	vo.AddFlags(execCmd.Flags())
+	execCmd.Flags().StringVar(&containerName, containerFlag, "", "Name of the container, defaults to the first container in which the sources are synchronized")
+	return execCmd
+}
+
+func NewShellCommand() *cobra.Command {
+	var (
+		o             Options
+		vo            VariablesOptions
+		containerName string
+	)
+	shellCmd := &cobra.Command{
+		Use:   "shell [--container name]",
+		Short: "Start a shell in a container of the component deployed by ododev dev, from the directory of the sources",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := vo.Complete()
+			if err != nil {
+				return err
+			}
+			err = o.Complete(vo.Variables)
+			if err != nil {
+				return err
+			}
+			return runExec(o, containerName, nil)
+		},
+	}
+	o.AddFlags(shellCmd.Flags())
+	vo.AddFlags(shellCmd.Flags())
+	shellCmd.Flags().StringVar(&containerName, containerFlag, "", "Name of the container, defaults to the first container in which the sources are synchronized")
+	return shellCmd
+}
+
+// runExec executes the command, or a shell if command is empty, in the container.
+// The command is attached to the terminal when the standard input is a terminal
+func runExec(o Options, containerName string, command []string) error {
+	// the manager is not started, it is used to execute commands in the container
+	mgr, err := manager.New(o.RestConfig, manager.Options{
+		Namespace: o.Namespace,
+	})
+	if err != nil {
+		return err
+	}
+	cli, err := client.New(o.RestConfig, client.Options{
+		Scheme: mgr.GetScheme(),
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx := signals.SetupSignalHandler()
+
+	spec, err := devfile.GetSpec(ctx, cli, o.Namespace, o.ComponentName)
+	if err != nil {
+		return fmt.Errorf("unable to get the spec of component %q, is ododev dev running? %w", o.ComponentName, err)
+	}
+
+	options := controller.ExecOptions{
+		Container: containerName,
+		Command:   command,
+		Stdin:     os.Stdin,
+		Stdout:    os.Stdout,
+		Stderr:    os.Stderr,
+	}
+	tty := term.TTY{
+		In:  os.Stdin,
+		Out: os.Stdout,
+		Raw: true,
+	}
+	if tty.IsTerminalIn() {
+		options.TTY = true
+		options.SizeQueue = tty.MonitorSize(tty.GetSize())
+		err = tty.Safe(func() error {
+			return controller.ExecInContainer(ctx, mgr, o.Namespace, o.ComponentName, *spec.Devfile, options)
+		})
+	} else {
+		err = controller.ExecInContainer(ctx, mgr, o.Namespace, o.ComponentName, *spec.Devfile, options)
+	}
+
+	var exitErr utilexec.ExitError
+	if errors.As(err, &exitErr) {
+		return ExitCodeError{
+			Code: exitErr.ExitStatus(),
+			Err:  fmt.Errorf("command exited with code %d", exitErr.ExitStatus()),
+		}
+	}
+	return err
+}
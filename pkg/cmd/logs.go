@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/kubectl/pkg/util/term"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/manager/signals"
+
+	"github.com/feloy/ododev/pkg/controller"
+)
+
+const (
+	followFlag    = "follow"
+	sinceFlag     = "since"
+	containerFlag = "container"
+)
+
+func NewLogsCommand() *cobra.Command {
+	var (
+		o          Options
+		vo         VariablesOptions
+		follow     bool
+		since      time.Duration
+		containers []string
+	)
+	logsCmd := &cobra.Command{
+		Use:   "logs",
+		Short: "Display the logs of the containers of the component deployed by ododev dev",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := vo.Complete()
+			if err != nil {
+				return err
+			}
+			err = o.Complete(vo.Variables)
+			if err != nil {
+				return err
+			}
+			return runLogs(o, controller.LogsOptions{
+				Follow:     follow,
+				Since:      since,
+				Containers: containers,
+				Color:      term.AllowsColorOutput(os.Stdout),
+			})
+		},
+	}
+	o.AddFlags(logsCmd.Flags())
+	vo.AddFlags(logsCmd.Flags())
+	logsCmd.Flags().BoolVarP(&follow, followFlag, "f", false, "Follow the logs, including after the containers restart or the pod is replaced")
+	logsCmd.Flags().DurationVar(&since, sinceFlag, 0, "Only display the logs more recent than this duration, for example 5m")
+	logsCmd.Flags().StringArrayVar(&containers, containerFlag, nil, "Name of a container of which the logs are displayed, all the containers by default (can be repeated)")
+	return logsCmd
+}
+
+func runLogs(o Options, options controller.LogsOptions) error {
+	// the manager is not started, it is used to access the pod
+	mgr, err := manager.New(o.RestConfig, manager.Options{
+		Namespace: o.Namespace,
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx := signals.SetupSignalHandler()
+	return controller.FollowLogs(ctx, mgr, o.Namespace, o.ComponentName, options, os.Stdout)
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/devfile/library/pkg/devfile"
+	"github.com/devfile/library/pkg/devfile/parser"
+	"github.com/spf13/pflag"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+)
+
+const (
+	namespaceFlag       = "namespace"
+	componentFlag       = "component"
+	devfileFlag         = "devfile"
+	kubeContextFlag     = "kube-context"
+	dotOdoDirectoryFlag = "odo-dir"
+)
+
+// envPrefix is the prefix of the environment variables which can be used instead of flags.
+// For example, ODODEV_NAMESPACE can be used instead of --namespace
+const envPrefix = "ODODEV_"
+
+// Options contains the values common to all commands
+type Options struct {
+	Namespace       string
+	ComponentName   string
+	DevfilePath     string
+	KubeContext     string
+	DotOdoDirectory string
+
+	// WorkingDir is the directory containing the sources to synchronize
+	WorkingDir string
+	// RestConfig is the configuration to access the cluster, for the selected context
+	RestConfig *rest.Config
+}
+
+func (o *Options) AddFlags(flags *pflag.FlagSet) {
+	flags.StringVarP(&o.Namespace, namespaceFlag, "n", "", "Namespace in which to deploy the component, defaults to the namespace of the kubeconfig context")
+	flags.StringVarP(&o.ComponentName, componentFlag, "c", "", "Name of the component, defaults to the metadata.name field of the devfile")
+	flags.StringVar(&o.DevfilePath, devfileFlag, "devfile.yaml", "Path of the devfile")
+	flags.StringVar(&o.KubeContext, kubeContextFlag, "", "Name of the kubeconfig context to use, defaults to the current context")
+	flags.StringVar(&o.DotOdoDirectory, dotOdoDirectoryFlag, ".odo", "Directory in which ododev stores its local files")
+}
+
+// Complete computes the default values.
+// variables are the values of the devfile variables passed by the user
+func (o *Options) Complete(variables map[string]string) error {
+	var err error
+	o.WorkingDir, err = os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	o.DevfilePath, err = filepath.Abs(o.DevfilePath)
+	if err != nil {
+		return err
+	}
+
+	if o.ComponentName == "" {
+		o.ComponentName, err = getComponentNameFromDevfile(o.DevfilePath, variables)
+		if err != nil {
+			return err
+		}
+	}
+
+	o.RestConfig, err = config.GetConfigWithContext(o.KubeContext)
+	if err != nil {
+		return err
+	}
+
+	if o.Namespace == "" {
+		o.Namespace, err = getNamespaceFromKubeconfig(o.KubeContext)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setFlagsFromEnv sets the value of the flags not set on the command line
+// from the environment variables ODODEV_<FLAG_NAME>, if defined
+func setFlagsFromEnv(flags *pflag.FlagSet) error {
+	var err error
+	flags.VisitAll(func(f *pflag.Flag) {
+		if err != nil || f.Changed {
+			return
+		}
+		val, ok := os.LookupEnv(envName(f.Name))
+		if !ok {
+			return
+		}
+		err = flags.Set(f.Name, val)
+	})
+	return err
+}
+
+func envName(flagName string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+func getComponentNameFromDevfile(devfilePath string, variables map[string]string) (string, error) {
+	devfileObj, _, err := devfile.ParseDevfileAndValidate(parser.ParserArgs{
+		Path:              devfilePath,
+		ExternalVariables: variables,
+	})
+	if err != nil {
+		return "", err
+	}
+	name := devfileObj.Data.GetMetadata().Name
+	if name == "" {
+		return "", errors.New("no metadata.name in devfile, please use the --component flag")
+	}
+	return name, nil
+}
+
+func getNamespaceFromKubeconfig(kubeContext string) (string, error) {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	// --kubeconfig flag is registered by the controller-runtime config package
+	if f := flag.Lookup("kubeconfig"); f != nil {
+		loadingRules.ExplicitPath = f.Value.String()
+	}
+	overrides := &clientcmd.ConfigOverrides{
+		CurrentContext: kubeContext,
+	}
+	ns, _, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides).Namespace()
+	if err != nil {
+		return "", fmt.Errorf("unable to get namespace from kubeconfig: %w", err)
+	}
+	return ns, nil
+}
@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+func TestEnvName(t *testing.T) {
+	tests := []struct {
+		flagName string
+		want     string
+	}{
+		{flagName: "namespace", want: "ODODEV_NAMESPACE"},
+		{flagName: "kube-context", want: "ODODEV_KUBE_CONTEXT"},
+		{flagName: "odo-dir", want: "ODODEV_ODO_DIR"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.flagName, func(t *testing.T) {
+			if got := envName(tt.flagName); got != tt.want {
+				t.Errorf("envName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetFlagsFromEnv(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		env  map[string]string
+		want Options
+	}{
+		{
+			name: "default values",
+			want: Options{
+				DevfilePath:     "devfile.yaml",
+				DotOdoDirectory: ".odo",
+			},
+		},
+		{
+			name: "values from env",
+			env: map[string]string{
+				"ODODEV_NAMESPACE":    "ns",
+				"ODODEV_KUBE_CONTEXT": "ctx",
+				"ODODEV_ODO_DIR":      ".ododev",
+			},
+			want: Options{
+				Namespace:       "ns",
+				DevfilePath:     "devfile.yaml",
+				KubeContext:     "ctx",
+				DotOdoDirectory: ".ododev",
+			},
+		},
+		{
+			name: "flags take precedence over env",
+			args: []string{"--namespace", "flag-ns", "-c", "flag-component"},
+			env: map[string]string{
+				"ODODEV_NAMESPACE": "ns",
+				"ODODEV_COMPONENT": "component",
+				"ODODEV_DEVFILE":   "other.yaml",
+			},
+			want: Options{
+				Namespace:       "flag-ns",
+				ComponentName:   "flag-component",
+				DevfilePath:     "other.yaml",
+				DotOdoDirectory: ".odo",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			var o Options
+			flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			o.AddFlags(flags)
+			if err := flags.Parse(tt.args); err != nil {
+				t.Fatal(err)
+			}
+			if err := setFlagsFromEnv(flags); err != nil {
+				t.Fatal(err)
+			}
+			if o != tt.want {
+				t.Errorf("options = %+v, want %+v", o, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetComponentNameFromDevfile(t *testing.T) {
+	tests := []struct {
+		name      string
+		devfile   string
+		variables map[string]string
+		want      string
+		wantErr   bool
+	}{
+		{
+			name: "name from metadata",
+			devfile: `schemaVersion: 2.2.0
+metadata:
+  name: my-component
+`,
+			want: "my-component",
+		},
+		{
+			name: "variables passed by the user",
+			devfile: `schemaVersion: 2.2.0
+metadata:
+  name: my-component
+variables:
+  image: image
+components:
+- name: runtime
+  container:
+    image: "{{image}}"
+`,
+			variables: map[string]string{"image": "other"},
+			want:      "my-component",
+		},
+		{
+			name: "no name in metadata",
+			devfile: `schemaVersion: 2.2.0
+metadata:
+  version: 1.0.0
+`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			devfilePath := filepath.Join(t.TempDir(), "devfile.yaml")
+			if err := os.WriteFile(devfilePath, []byte(tt.devfile), 0644); err != nil {
+				t.Fatal(err)
+			}
+			got, err := getComponentNameFromDevfile(devfilePath, tt.variables)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getComponentNameFromDevfile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("getComponentNameFromDevfile() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetNamespaceFromKubeconfig(t *testing.T) {
+	kubeconfig := filepath.Join(t.TempDir(), "config")
+	err := os.WriteFile(kubeconfig, []byte(`apiVersion: v1
+kind: Config
+clusters:
+- name: cluster
+  cluster:
+    server: https://localhost:6443
+users:
+- name: user
+contexts:
+- name: current
+  context:
+    cluster: cluster
+    user: user
+    namespace: current-ns
+- name: other
+  context:
+    cluster: cluster
+    user: user
+    namespace: other-ns
+- name: no-namespace
+  context:
+    cluster: cluster
+    user: user
+current-context: current
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("KUBECONFIG", kubeconfig)
+
+	tests := []struct {
+		kubeContext string
+		want        string
+	}{
+		{kubeContext: "", want: "current-ns"},
+		{kubeContext: "other", want: "other-ns"},
+		{kubeContext: "no-namespace", want: "default"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.kubeContext, func(t *testing.T) {
+			got, err := getNamespaceFromKubeconfig(tt.kubeContext)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("getNamespaceFromKubeconfig() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
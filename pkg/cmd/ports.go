@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/pflag"
+
+	"github.com/feloy/ododev/pkg/devfile"
+)
+
+const (
+	portForwardFlag = "port-forward"
+
+	// portsFile is the file of the .odo directory in which the local ports assigned to the endpoints are remembered
+	portsFile = "ports.json"
+)
+
+// PortOptions contains the local ports requested by the user for the endpoints
+type PortOptions struct {
+	PortForwards []string
+
+	// Pinned are the local ports requested by the user, indexed by endpoint name
+	Pinned map[string]int
+}
+
+func (o *PortOptions) AddFlags(flags *pflag.FlagSet) {
+	flags.StringArrayVar(&o.PortForwards, portForwardFlag, nil, "Local port to which an endpoint is forwarded, in the form ENDPOINT=LOCAL_PORT (can be repeated)")
+}
+
+// Complete parses the local ports passed with flags
+func (o *PortOptions) Complete() error {
+	o.Pinned = map[string]int{}
+	for _, v := range o.PortForwards {
+		parts := strings.SplitN(v, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return fmt.Errorf("invalid port forward %q, should be in the form ENDPOINT=LOCAL_PORT", v)
+		}
+		port, err := strconv.Atoi(parts[1])
+		if err != nil || port <= 0 || port > 65535 {
+			return fmt.Errorf("invalid local port %q for endpoint %q", parts[1], parts[0])
+		}
+		o.Pinned[strings.TrimSpace(parts[0])] = port
+	}
+	return nil
+}
+
+// readPorts returns the local ports assigned to the endpoints of the component during the previous session,
+// indexed by endpoint name
+func readPorts(dotOdoDirectory string, componentName string) (map[string]int, error) {
+	ports, err := readPortsFile(dotOdoDirectory)
+	if err != nil {
+		return nil, err
+	}
+	return ports[componentName], nil
+}
+
+// writePorts remembers the local ports assigned to the endpoints of the component
+func writePorts(dotOdoDirectory string, componentName string, forwarded []devfile.ForwardedPort) error {
+	ports, err := readPortsFile(dotOdoDirectory)
+	if err != nil {
+		return err
+	}
+	if ports == nil {
+		ports = map[string]map[string]int{}
+	}
+	componentPorts := make(map[string]int, len(forwarded))
+	for _, port := range forwarded {
+		componentPorts[port.EndpointName] = port.LocalPort
+	}
+	ports[componentName] = componentPorts
+	content, err := json.MarshalIndent(ports, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dotOdoDirectory, portsFile), content, 0644)
+}
+
+// readPortsFile returns the local ports of the endpoints, indexed by component name and endpoint name
+func readPortsFile(dotOdoDirectory string) (map[string]map[string]int, error) {
+	content, err := os.ReadFile(filepath.Join(dotOdoDirectory, portsFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var ports map[string]map[string]int
+	err = json.Unmarshal(content, &ports)
+	if err != nil {
+		return nil, err
+	}
+	return ports, nil
+}
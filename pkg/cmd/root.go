@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"flag"
+
+	"github.com/spf13/cobra"
+)
+
+// NewRootCommand returns the ododev command and its subcommands
+func NewRootCommand() *cobra.Command {
+	rootCmd := &cobra.Command{
+		Use:           "ododev",
+		Short:         "odo dev implementation using the controller-runtime library",
+		SilenceUsage:  true,
+		SilenceErrors: false,
+		// set the values of the flags not passed on the command line from the environment
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return setFlagsFromEnv(cmd.Flags())
+		},
+	}
+
+	// --kubeconfig flag, registered by the controller-runtime config package
+	rootCmd.PersistentFlags().AddGoFlagSet(flag.CommandLine)
+
+	rootCmd.AddCommand(
+		NewDevCommand(),
+		NewTestCommand(),
+		NewLogsCommand(),
+		NewExecCommand(),
+		NewShellCommand(),
+	)
+	return rootCmd
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/manager/signals"
+
+	"github.com/feloy/ododev/pkg/controller"
+	"github.com/feloy/ododev/pkg/devfile"
+)
+
+const timeoutFlag = "timeout"
+
+func NewTestCommand() *cobra.Command {
+	var (
+		o       Options
+		vo      VariablesOptions
+		timeout time.Duration
+	)
+	testCmd := &cobra.Command{
+		Use:   "test",
+		Short: "Execute the default test command in the container of the component deployed by ododev dev",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := vo.Complete()
+			if err != nil {
+				return err
+			}
+			err = o.Complete(vo.Variables)
+			if err != nil {
+				return err
+			}
+			return runTest(o, timeout)
+		},
+	}
+	o.AddFlags(testCmd.Flags())
+	vo.AddFlags(testCmd.Flags())
+	testCmd.Flags().DurationVar(&timeout, timeoutFlag, 5*time.Minute, "Maximum time to wait for the sources to be synchronized")
+	return testCmd
+}
+
+func runTest(o Options, timeout time.Duration) error {
+	// the manager is not started, it is used to execute commands in the container
+	mgr, err := manager.New(o.RestConfig, manager.Options{
+		Namespace: o.Namespace,
+	})
+	if err != nil {
+		return err
+	}
+	cli, err := client.New(o.RestConfig, client.Options{
+		Scheme: mgr.GetScheme(),
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx := signals.SetupSignalHandler()
+
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	spec, err := waitSourcesSynced(waitCtx, cli, mgr, o.Namespace, o.ComponentName)
+	if err != nil {
+		return err
+	}
+
+	result, err := controller.ExecTestCommand(ctx, mgr, o.Namespace, o.ComponentName, *spec.Devfile, os.Stdout)
+	if err != nil {
+		return err
+	}
+	err = devfile.SetTestResult(ctx, cli, o.Namespace, o.ComponentName, result)
+	if err != nil {
+		return err
+	}
+	if result.ExitCode != 0 {
+		return ExitCodeError{
+			Code: result.ExitCode,
+			Err:  fmt.Errorf("test command %q exited with code %d", result.Command, result.ExitCode),
+		}
+	}
+	return nil
+}
+
+// waitSourcesSynced waits until the status shows that the sources referenced by the current spec
+// have been synchronized to the container, and returns the spec
+func waitSourcesSynced(ctx context.Context, cli client.Client, mgr manager.Manager, namespace string, componentName string) (*devfile.SpecContent, error) {
+	statusWatcher, err := devfile.WatchStatus(ctx, cli, mgr, namespace, componentName)
+	if err != nil {
+		return nil, err
+	}
+	// the message is displayed once, the status being modified several times before the sources are synchronized
+	waiting := false
+	for {
+		spec, err := devfile.GetSpec(ctx, cli, namespace, componentName)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get the spec of component %q, is ododev dev running? %w", componentName, err)
+		}
+		status, err := devfile.GetStatus(ctx, cli, namespace, componentName)
+		if err == nil && isSynced(spec, status) {
+			return spec, nil
+		}
+
+		if !waiting {
+			fmt.Println("waiting for the sources to be synchronized")
+			waiting = true
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("sources not synchronized: %w", ctx.Err())
+		case _, ok := <-statusWatcher:
+			if !ok {
+				return nil, fmt.Errorf("status of component %q not watched anymore", componentName)
+			}
+		}
+	}
+}
+
+// isSynced returns true if the synced generations in the status are the ones of the spec
+func isSynced(spec *devfile.SpecContent, status devfile.StatusContent) bool {
+	return pointer.StringDeref(status.SyncedCompleteGeneration, "") == spec.CompleteSyncGeneration &&
+		pointer.StringDeref(status.SyncedIncrementalGeneration, "") == spec.IncrementalSyncGeneration
+}
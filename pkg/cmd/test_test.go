@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"testing"
+
+	"k8s.io/utils/pointer"
+
+	"github.com/feloy/ododev/pkg/devfile"
+)
+
+func TestIsSynced(t *testing.T) {
+	tests := []struct {
+		name   string
+		spec   devfile.SpecContent
+		status devfile.StatusContent
+		want   bool
+	}{
+		{
+			name:   "nothing synced yet",
+			spec:   devfile.SpecContent{CompleteSyncGeneration: "a"},
+			status: devfile.StatusContent{},
+			want:   false,
+		},
+		{
+			name:   "complete archive synced",
+			spec:   devfile.SpecContent{CompleteSyncGeneration: "a"},
+			status: devfile.StatusContent{SyncedCompleteGeneration: pointer.String("a")},
+			want:   true,
+		},
+		{
+			name:   "other complete archive synced",
+			spec:   devfile.SpecContent{CompleteSyncGeneration: "a"},
+			status: devfile.StatusContent{SyncedCompleteGeneration: pointer.String("b")},
+			want:   false,
+		},
+		{
+			name: "incremental archive not synced",
+			spec: devfile.SpecContent{
+				CompleteSyncGeneration:    "a",
+				IncrementalSyncGeneration: "c",
+			},
+			status: devfile.StatusContent{SyncedCompleteGeneration: pointer.String("a")},
+			want:   false,
+		},
+		{
+			name: "incremental archive synced",
+			spec: devfile.SpecContent{
+				CompleteSyncGeneration:    "a",
+				IncrementalSyncGeneration: "c",
+			},
+			status: devfile.StatusContent{
+				SyncedCompleteGeneration:    pointer.String("a"),
+				SyncedIncrementalGeneration: pointer.String("c"),
+			},
+			want: true,
+		},
+		{
+			name: "incremental archive synced, no more modified files",
+			spec: devfile.SpecContent{
+				CompleteSyncGeneration: "a",
+			},
+			status: devfile.StatusContent{
+				SyncedCompleteGeneration:    pointer.String("a"),
+				SyncedIncrementalGeneration: pointer.String("c"),
+			},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isSynced(&tt.spec, tt.status); got != tt.want {
+				t.Errorf("isSynced() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+const (
+	varFlag     = "var"
+	varFileFlag = "var-file"
+)
+
+// VariablesOptions contains the values of the devfile variables passed by the user
+type VariablesOptions struct {
+	Vars    []string
+	VarFile string
+
+	// Variables is the result of the merge of the variables from the file and from the flags
+	Variables map[string]string
+}
+
+func (o *VariablesOptions) AddFlags(flags *pflag.FlagSet) {
+	flags.StringArrayVar(&o.Vars, varFlag, nil, "Value of a devfile variable, in the form KEY=VALUE, overriding the value in the devfile (can be repeated)")
+	flags.StringVar(&o.VarFile, varFileFlag, "", "File containing values of devfile variables, one KEY=VALUE per line")
+}
+
+// Complete merges the variables from the file and from the flags.
+// The values passed with flags override the values of the file
+func (o *VariablesOptions) Complete() error {
+	o.Variables = map[string]string{}
+	if o.VarFile != "" {
+		content, err := os.ReadFile(o.VarFile)
+		if err != nil {
+			return err
+		}
+		scanner := bufio.NewScanner(strings.NewReader(string(content)))
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			key, value, err := parseVariable(line)
+			if err != nil {
+				return fmt.Errorf("in file %s: %w", o.VarFile, err)
+			}
+			o.Variables[key] = value
+		}
+		if err = scanner.Err(); err != nil {
+			return err
+		}
+	}
+	for _, v := range o.Vars {
+		key, value, err := parseVariable(v)
+		if err != nil {
+			return err
+		}
+		o.Variables[key] = value
+	}
+	return nil
+}
+
+func parseVariable(v string) (string, string, error) {
+	parts := strings.SplitN(v, "=", 2)
+	if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+		return "", "", fmt.Errorf("invalid variable %q, should be in the form KEY=VALUE", v)
+	}
+	return strings.TrimSpace(parts[0]), parts[1], nil
+}
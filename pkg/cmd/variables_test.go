@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseVariable(t *testing.T) {
+	tests := []struct {
+		name      string
+		v         string
+		wantKey   string
+		wantValue string
+		wantErr   bool
+	}{
+		{
+			name:      "key and value",
+			v:         "KEY=value",
+			wantKey:   "KEY",
+			wantValue: "value",
+		},
+		{
+			name:      "value containing an equal sign",
+			v:         "KEY=a=b",
+			wantKey:   "KEY",
+			wantValue: "a=b",
+		},
+		{
+			name:    "empty value",
+			v:       "KEY=",
+			wantKey: "KEY",
+		},
+		{
+			name:      "spaces around the key",
+			v:         " KEY =value",
+			wantKey:   "KEY",
+			wantValue: "value",
+		},
+		{
+			name:    "no equal sign",
+			v:       "KEY",
+			wantErr: true,
+		},
+		{
+			name:    "empty key",
+			v:       "=value",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, value, err := parseVariable(tt.v)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseVariable() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if key != tt.wantKey || value != tt.wantValue {
+				t.Errorf("parseVariable() = %q, %q, want %q, %q", key, value, tt.wantKey, tt.wantValue)
+			}
+		})
+	}
+}
+
+func TestVariablesOptionsComplete(t *testing.T) {
+	tests := []struct {
+		name    string
+		vars    []string
+		varFile string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name: "no variable",
+			want: map[string]string{},
+		},
+		{
+			name: "variables from flags",
+			vars: []string{"A=1", "B=2", "A=3"},
+			want: map[string]string{"A": "3", "B": "2"},
+		},
+		{
+			name:    "variables from file, with comments and empty lines",
+			varFile: "# comment\nA=1\n\n  B=2  \n",
+			want:    map[string]string{"A": "1", "B": "2"},
+		},
+		{
+			name:    "flags override file",
+			vars:    []string{"B=3"},
+			varFile: "A=1\nB=2\n",
+			want:    map[string]string{"A": "1", "B": "3"},
+		},
+		{
+			name:    "invalid variable in file",
+			varFile: "A\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid variable in flags",
+			vars:    []string{"=1"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := VariablesOptions{
+				Vars: tt.vars,
+			}
+			if tt.varFile != "" {
+				o.VarFile = filepath.Join(t.TempDir(), "vars")
+				if err := os.WriteFile(o.VarFile, []byte(tt.varFile), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			err := o.Complete()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Complete() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(o.Variables, tt.want) {
+				t.Errorf("Variables = %v, want %v", o.Variables, tt.want)
+			}
+		})
+	}
+}
@@ -17,7 +17,26 @@ import (
 
 // ExecCMDInContainer execute command in the container of a pod, pass an empty string for containerName to execute in the first container of the pod
 func Exec(ctx context.Context, client client.Client, mgr manager.Manager, pod *corev1.Pod, containerName string, cmd []string, stdout io.Writer, stderr io.Writer, stdin io.Reader, tty bool) error {
+	return stream(ctx, mgr, pod, containerName, cmd, remotecommand.StreamOptions{
+		Stdin:  stdin,
+		Stdout: stdout,
+		Stderr: stderr,
+		Tty:    tty,
+	})
+}
+
+// ExecTTY executes a command in the container of a pod, attached to a terminal.
+// The size of the remote terminal is changed every time sizeQueue returns a new size
+func ExecTTY(ctx context.Context, mgr manager.Manager, pod *corev1.Pod, containerName string, cmd []string, stdin io.Reader, stdout io.Writer, sizeQueue remotecommand.TerminalSizeQueue) error {
+	return stream(ctx, mgr, pod, containerName, cmd, remotecommand.StreamOptions{
+		Stdin:             stdin,
+		Stdout:            stdout,
+		Tty:               true,
+		TerminalSizeQueue: sizeQueue,
+	})
+}
 
+func stream(ctx context.Context, mgr manager.Manager, pod *corev1.Pod, containerName string, cmd []string, streams remotecommand.StreamOptions) error {
 	podGVK := corev1.SchemeGroupVersion.WithKind("Pod")
 
 	rest, err := apiutil.RESTClientForGVK(podGVK, true, mgr.GetConfig(), serializer.NewCodecFactory(mgr.GetScheme()))
@@ -27,10 +46,10 @@ func Exec(ctx context.Context, client client.Client, mgr manager.Manager, pod *c
 
 	podExecOptions := corev1.PodExecOptions{
 		Command: cmd,
-		Stdin:   stdin != nil,
-		Stdout:  stdout != nil,
-		Stderr:  stderr != nil,
-		TTY:     tty,
+		Stdin:   streams.Stdin != nil,
+		Stdout:  streams.Stdout != nil,
+		Stderr:  streams.Stderr != nil,
+		TTY:     streams.Tty,
 	}
 
 	// If a container name was passed in, set it in the exec options, otherwise leave it blank
@@ -54,12 +73,7 @@ func Exec(ctx context.Context, client client.Client, mgr manager.Manager, pod *c
 		return fmt.Errorf("unable execute command via SPDY: %w", err)
 	}
 	// initialize the transport of the standard shell streams
-	err = exec.Stream(remotecommand.StreamOptions{
-		Stdin:  stdin,
-		Stdout: stdout,
-		Stderr: stderr,
-		Tty:    tty,
-	})
+	err = exec.Stream(streams)
 	if err != nil {
 		return fmt.Errorf("error while streaming command: %w", err)
 	}
@@ -3,7 +3,10 @@ package container
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"io"
+	"path"
+	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -32,3 +35,55 @@ func ExtractTarToContainer(ctx context.Context, client client.Client, mgr manage
 	}
 	return err
 }
+
+// RemoveFiles removes the files, passed as paths relative to targetPath, from the container.
+// It refuses to remove any file outside of targetPath
+func RemoveFiles(ctx context.Context, client client.Client, mgr manager.Manager, pod *corev1.Pod, containerName string, targetPath string, files []string) error {
+	if len(files) == 0 {
+		return nil
+	}
+
+	paths, err := getPathsToRemove(targetPath, files)
+	if err != nil {
+		return err
+	}
+
+	entryLog := log.Log.WithName("watch")
+
+	// paths are passed through stdin, to not be limited by the length of the command line
+	var stdin bytes.Buffer
+	for _, p := range paths {
+		stdin.WriteString(p)
+		stdin.WriteByte(0)
+	}
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	cmdArr := []string{"xargs", "-0", "rm", "-rf", "--"}
+	err = Exec(ctx, client, mgr, pod, containerName, cmdArr, &stdout, &stderr, &stdin, false)
+	if err != nil {
+		entryLog.Info("error removing files", "stdout", stdout.String(), "stderr", stderr.String())
+	}
+	return err
+}
+
+// getPathsToRemove returns the absolute paths of the files in the container,
+// or an error if a file is not strictly inside targetPath
+func getPathsToRemove(targetPath string, files []string) ([]string, error) {
+	root := path.Clean(targetPath)
+	if !path.IsAbs(root) || root == "/" {
+		return nil, fmt.Errorf("refusing to remove files from %q, the sync root must be an absolute path other than /", targetPath)
+	}
+	result := make([]string, 0, len(files))
+	for _, file := range files {
+		if file == "" || path.IsAbs(file) {
+			return nil, fmt.Errorf("refusing to remove %q, it must be a path relative to %q", file, root)
+		}
+		p := path.Join(root, file)
+		if !strings.HasPrefix(p, root+"/") {
+			return nil, fmt.Errorf("refusing to remove %q, it is outside of %q", file, root)
+		}
+		result = append(result, p)
+	}
+	return result, nil
+}
@@ -0,0 +1,103 @@
+package container
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGetPathsToRemove(t *testing.T) {
+	tests := []struct {
+		name       string
+		targetPath string
+		files      []string
+		want       []string
+		wantErr    bool
+	}{
+		{
+			name:       "files relative to target",
+			targetPath: "/projects",
+			files:      []string{"a", "dir/b"},
+			want:       []string{"/projects/a", "/projects/dir/b"},
+		},
+		{
+			name:       "target with trailing slash",
+			targetPath: "/projects/",
+			files:      []string{"a"},
+			want:       []string{"/projects/a"},
+		},
+		{
+			name:       "path cleaned inside target",
+			targetPath: "/projects",
+			files:      []string{"dir/../a", "./b"},
+			want:       []string{"/projects/a", "/projects/b"},
+		},
+		{
+			name:       "no file",
+			targetPath: "/projects",
+			files:      nil,
+			want:       []string{},
+		},
+		{
+			name:       "path escaping target",
+			targetPath: "/projects",
+			files:      []string{"a", "../etc/passwd"},
+			wantErr:    true,
+		},
+		{
+			name:       "path escaping target to a sibling with the same prefix",
+			targetPath: "/projects",
+			files:      []string{"../projects2/a"},
+			wantErr:    true,
+		},
+		{
+			name:       "target itself",
+			targetPath: "/projects",
+			files:      []string{"."},
+			wantErr:    true,
+		},
+		{
+			name:       "target itself through parent",
+			targetPath: "/projects",
+			files:      []string{"dir/.."},
+			wantErr:    true,
+		},
+		{
+			name:       "absolute file",
+			targetPath: "/projects",
+			files:      []string{"/etc/passwd"},
+			wantErr:    true,
+		},
+		{
+			name:       "empty file",
+			targetPath: "/projects",
+			files:      []string{""},
+			wantErr:    true,
+		},
+		{
+			name:       "relative target",
+			targetPath: "projects",
+			files:      []string{"a"},
+			wantErr:    true,
+		},
+		{
+			name:       "root target",
+			targetPath: "/",
+			files:      []string{"a"},
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getPathsToRemove(tt.targetPath, tt.files)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getPathsToRemove() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getPathsToRemove() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package container
 
 import (
+	"errors"
 	"io"
 	"net/http"
 
@@ -8,22 +9,35 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/serializer"
 	"k8s.io/client-go/tools/portforward"
 	"k8s.io/client-go/transport/spdy"
-	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 )
 
-func SetupPortForwarding(mgr manager.Manager, client client.Client, pod *corev1.Pod, portPairs []string, out io.Writer, errOut io.Writer) (chan struct{}, error) {
+// ErrLostConnection is returned by ForwardPort when the connection to the pod is lost
+var ErrLostConnection = errors.New("lost connection to pod")
+
+// ForwardPort forwards a local port to a port of the pod, as a local:remote pair, until stopChan is closed
+// or the connection to the pod is lost. ready is called when the connection to the pod is established
+// and the local port is listening
+func ForwardPort(
+	mgr manager.Manager,
+	pod *corev1.Pod,
+	portPair string,
+	stopChan <-chan struct{},
+	ready func(),
+	out io.Writer,
+	errOut io.Writer,
+) error {
 	transport, upgrader, err := spdy.RoundTripperFor(mgr.GetConfig())
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	podGVK := corev1.SchemeGroupVersion.WithKind("Pod")
 
 	rest, err := apiutil.RESTClientForGVK(podGVK, true, mgr.GetConfig(), serializer.NewCodecFactory(mgr.GetScheme()))
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	req := rest.
@@ -34,18 +48,34 @@ func SetupPortForwarding(mgr manager.Manager, client client.Client, pod *corev1.
 		SubResource("portforward")
 
 	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, "POST", req.URL())
-	stopChan := make(chan struct{}, 1)
-	// passing nil for readyChan because it's eventually being closed if it's not nil
-	// passing nil for out because we only care for error, not for output messages; we want to print our own messages
-	fw, err := portforward.New(dialer, portPairs, stopChan, nil, out, errOut)
+	readyChan := make(chan struct{})
+	fw, err := portforward.New(dialer, []string{portPair}, stopChan, readyChan, out, errOut)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	// start port-forwarding
+	errChan := make(chan error, 1)
 	go func() {
-		fw.ForwardPorts()
+		errChan <- fw.ForwardPorts()
 	}()
 
-	return stopChan, nil
+	select {
+	case err = <-errChan:
+		return err
+	case <-readyChan:
+	}
+
+	ready()
+
+	err = <-errChan
+	if err != nil {
+		return err
+	}
+	// ForwardPorts returns without error when the connection is lost
+	select {
+	case <-stopChan:
+		return nil
+	default:
+		return ErrLostConnection
+	}
 }
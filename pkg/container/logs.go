@@ -0,0 +1,39 @@
+package container
+
+import (
+	"context"
+	"io"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/kubectl/pkg/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// StreamLogs copies the logs of a container of the pod to out, using the pods/log subresource.
+// When opts.Follow is set, it returns when the container terminates or the context is done
+func StreamLogs(ctx context.Context, mgr manager.Manager, pod *corev1.Pod, opts *corev1.PodLogOptions, out io.Writer) error {
+	podGVK := corev1.SchemeGroupVersion.WithKind("Pod")
+
+	rest, err := apiutil.RESTClientForGVK(podGVK, true, mgr.GetConfig(), serializer.NewCodecFactory(mgr.GetScheme()))
+	if err != nil {
+		return err
+	}
+
+	stream, err := rest.
+		Get().
+		Namespace(pod.GetNamespace()).
+		Resource("pods").
+		Name(pod.GetName()).
+		SubResource("log").
+		VersionedParams(opts, scheme.ParameterCodec).
+		Stream(ctx)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	_, err = io.Copy(out, stream)
+	return err
+}
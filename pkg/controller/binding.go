@@ -27,10 +27,7 @@ func checkServiceBindingsInjectionDone(ctx context.Context, cli client.Client, n
 	}
 
 	for _, binding := range list.Items {
-		app := binding.Spec.Application
-		if app.Group != appsv1.SchemeGroupVersion.Group ||
-			app.Version != appsv1.SchemeGroupVersion.Version ||
-			(app.Kind != "Deployment" && app.Resource != "deployments") {
+		if !isBindingForDeployment(binding, getDeploymentName(componentName)) {
 			continue
 		}
 		if injected := meta.IsStatusConditionTrue(binding.Status.Conditions, bindingApis.InjectionReady); !injected {
@@ -39,3 +36,12 @@ func checkServiceBindingsInjectionDone(ctx context.Context, cli client.Client, n
 	}
 	return true, nil
 }
+
+// isBindingForDeployment returns true if the application of the binding is the deployment
+func isBindingForDeployment(binding bindingApi.ServiceBinding, deploymentName string) bool {
+	app := binding.Spec.Application
+	return app.Group == appsv1.SchemeGroupVersion.Group &&
+		app.Version == appsv1.SchemeGroupVersion.Version &&
+		(app.Kind == "Deployment" || app.Resource == "deployments") &&
+		app.Name == deploymentName
+}
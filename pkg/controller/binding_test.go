@@ -0,0 +1,50 @@
+package controller
+
+import (
+	"testing"
+
+	bindingApi "github.com/redhat-developer/service-binding-operator/apis/binding/v1alpha1"
+)
+
+func TestIsBindingForDeployment(t *testing.T) {
+	tests := []struct {
+		name string
+		ref  bindingApi.Ref
+		want bool
+	}{
+		{
+			name: "deployment by kind",
+			ref:  bindingApi.Ref{Group: "apps", Version: "v1", Kind: "Deployment", Name: "my-component-app"},
+			want: true,
+		},
+		{
+			name: "deployment by resource",
+			ref:  bindingApi.Ref{Group: "apps", Version: "v1", Resource: "deployments", Name: "my-component-app"},
+			want: true,
+		},
+		{
+			name: "deployment of another component",
+			ref:  bindingApi.Ref{Group: "apps", Version: "v1", Kind: "Deployment", Name: "other-app"},
+			want: false,
+		},
+		{
+			name: "statefulset",
+			ref:  bindingApi.Ref{Group: "apps", Version: "v1", Kind: "StatefulSet", Name: "my-component-app"},
+			want: false,
+		},
+		{
+			name: "other group",
+			ref:  bindingApi.Ref{Group: "example.com", Version: "v1", Kind: "Deployment", Name: "my-component-app"},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var binding bindingApi.ServiceBinding
+			binding.Spec.Application.Ref = tt.ref
+			if got := isBindingForDeployment(binding, getDeploymentName("my-component")); got != tt.want {
+				t.Errorf("isBindingForDeployment() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/feloy/ododev/pkg/devfile"
+	"github.com/feloy/ododev/pkg/libdevfile"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// commandRunner executes the tree of a devfile command in the containers of the pod,
+// and reports the progress of the sub-commands of composite commands
+type commandRunner struct {
+	client      client.Client
+	mgr         manager.Manager
+	pod         *corev1.Pod
+	syncTargets []libdevfile.SyncTarget
+	setStatus   func(status devfile.StatusContent) error
+
+	mu       sync.Mutex
+	progress []devfile.SubCommandStatus
+}
+
+// newCommandRunner returns a runner executing commands in the pod.
+// setStatus is used to report the progress of composite commands, and can be nil
+func newCommandRunner(client client.Client, mgr manager.Manager, pod *corev1.Pod, syncTargets []libdevfile.SyncTarget, setStatus func(status devfile.StatusContent) error) *commandRunner {
+	return &commandRunner{
+		client:      client,
+		mgr:         mgr,
+		pod:         pod,
+		syncTargets: syncTargets,
+		setStatus:   setStatus,
+	}
+}
+
+// run executes the commands of the tree, sequentially or in parallel as defined by the composite commands,
+// and sends their output to output. The error of the first sub-command failing is returned
+func (c *commandRunner) run(ctx context.Context, tree libdevfile.CommandTree, output io.Writer) error {
+	c.mu.Lock()
+	// an empty progress removes the progress of a previous composite command from the status
+	c.progress = []devfile.SubCommandStatus{}
+	if tree.IsComposite() {
+		for _, leaf := range tree.Leaves() {
+			c.progress = append(c.progress, devfile.SubCommandStatus{
+				Command: leaf.Id,
+				State:   devfile.SubCommandPending,
+			})
+		}
+	}
+	c.reportLocked()
+	c.mu.Unlock()
+
+	return c.runTree(ctx, tree, 0, output)
+}
+
+// runTree executes the commands of the tree, first is the index of the first leaf of the tree in the progress
+func (c *commandRunner) runTree(ctx context.Context, tree libdevfile.CommandTree, first int, output io.Writer) error {
+	if !tree.IsComposite() {
+		c.setState(first, devfile.SubCommandRunning, nil)
+		err := ExecDevfileCommand(ctx, c.client, c.mgr, c.pod, getWorkingDir(c.syncTargets, tree.Command), tree.Command, output)
+		if err != nil {
+			exitCode := getExitCode(err)
+			c.setState(first, devfile.SubCommandFailed, &exitCode)
+			return err
+		}
+		exitCode := 0
+		c.setState(first, devfile.SubCommandSucceeded, &exitCode)
+		return nil
+	}
+
+	if !tree.Parallel {
+		index := first
+		for _, sub := range tree.SubCommands {
+			err := c.runTree(ctx, sub, index, output)
+			if err != nil {
+				return err
+			}
+			index += len(sub.Leaves())
+		}
+		return nil
+	}
+
+	errs := make([]error, len(tree.SubCommands))
+	var wg sync.WaitGroup
+	index := first
+	for i, sub := range tree.SubCommands {
+		wg.Add(1)
+		go func(i int, sub libdevfile.CommandTree, index int) {
+			defer wg.Done()
+			errs[i] = c.runTree(ctx, sub, index, output)
+		}(i, sub, index)
+		index += len(sub.Leaves())
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setState sets the state of the sub-command at index in the progress, if the command is a composite one
+func (c *commandRunner) setState(index int, state devfile.SubCommandState, exitCode *int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if index >= len(c.progress) {
+		return
+	}
+	c.progress[index].State = state
+	c.progress[index].ExitCode = exitCode
+	c.reportLocked()
+}
+
+// reportLocked sets the progress in the status, c.mu must be held
+func (c *commandRunner) reportLocked() {
+	if c.setStatus == nil {
+		return
+	}
+	progress := make([]devfile.SubCommandStatus, len(c.progress))
+	copy(progress, c.progress)
+	_ = c.setStatus(devfile.StatusContent{
+		SubCommands: progress,
+	})
+}
+
+// checkExecCommands returns an error if a command of the tree is neither an exec command nor a composite command
+func checkExecCommands(tree libdevfile.CommandTree) error {
+	for _, leaf := range tree.Leaves() {
+		if leaf.Exec == nil {
+			return fmt.Errorf("command %q is not an exec command", leaf.Id)
+		}
+	}
+	return nil
+}
+
+// stopCommands stops the exec commands of the tree executed in the pod
+func stopCommands(ctx context.Context, client client.Client, mgr manager.Manager, pod *corev1.Pod, tree libdevfile.CommandTree) error {
+	for _, leaf := range tree.Leaves() {
+		if leaf.Exec == nil {
+			continue
+		}
+		err := StopDevfileCommand(ctx, client, mgr, pod, leaf)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
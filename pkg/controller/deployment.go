@@ -1,24 +1,38 @@
 package controller
 
 import (
+	"fmt"
+
 	"github.com/devfile/library/pkg/devfile/generator"
 	"github.com/devfile/library/pkg/devfile/parser"
 	"github.com/devfile/library/pkg/devfile/parser/data/v2/common"
 
+	"github.com/feloy/ododev/pkg/libdevfile"
+
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/utils/pointer"
 )
 
+// initContainerNameMaxLen is the maximum length of the name of an init container, without its index
+const initContainerNameMaxLen = 55
+
 func buildDeployment(devfileObj parser.DevfileObj, componentName string, namespace string) (*appsv1.Deployment, error) {
 	containers, err := generator.GetContainers(devfileObj, common.DevfileOptions{})
 	if err != nil {
 		return nil, err
 	}
 
+	// GetInitContainers only handles apply commands
 	initContainers, err := generator.GetInitContainers(devfileObj)
 	if err != nil {
 		return nil, err
 	}
+	execInitContainers, err := getExecInitContainers(devfileObj, containers, len(initContainers))
+	if err != nil {
+		return nil, err
+	}
+	initContainers = append(initContainers, execInitContainers...)
 
 	selectorLabels := map[string]string{
 		"component": componentName,
@@ -46,3 +60,54 @@ func buildDeployment(devfileObj parser.DevfileObj, componentName string, namespa
 func getDeploymentName(componentName string) string {
 	return componentName + "-app"
 }
+
+// getExecInitContainers returns the init containers executing the exec commands of the preStart events,
+// in the containers referenced by the commands. The init containers are numbered from first+1
+func getExecInitContainers(devfileObj parser.DevfileObj, containers []corev1.Container, first int) ([]corev1.Container, error) {
+	preStartCmds, err := libdevfile.GetEventCommands(devfileObj, devfileObj.Data.GetEvents().PreStart)
+	if err != nil {
+		return nil, err
+	}
+	var result []corev1.Container
+	i := first
+	for _, tree := range preStartCmds {
+		for _, cmd := range tree.Leaves() {
+			if cmd.Exec == nil {
+				continue
+			}
+			for _, container := range containers {
+				if container.Name != cmd.Exec.Component {
+					continue
+				}
+				i++
+				initContainer := *container.DeepCopy()
+				// same naming convention as the init containers created by GetInitContainers
+				name := fmt.Sprintf("%s-%s", container.Name, cmd.Id)
+				if len(name) > initContainerNameMaxLen {
+					name = name[:initContainerNameMaxLen]
+				}
+				initContainer.Name = fmt.Sprintf("%s-%d", name, i)
+				initContainer.Command = []string{"/bin/sh", "-c"}
+				initContainer.Args = []string{getShellCommandLine(cmd.Exec, getEnvValue(container, generator.EnvProjectsSrc))}
+				// the ports, probes and lifecycle handlers are not allowed for init containers
+				initContainer.Ports = nil
+				initContainer.LivenessProbe = nil
+				initContainer.ReadinessProbe = nil
+				initContainer.StartupProbe = nil
+				initContainer.Lifecycle = nil
+				result = append(result, initContainer)
+			}
+		}
+	}
+	return result, nil
+}
+
+// getEnvValue returns the value of the env var defined in the container, or an empty string
+func getEnvValue(container corev1.Container, name string) string {
+	for _, env := range container.Env {
+		if env.Name == name {
+			return env.Value
+		}
+	}
+	return ""
+}
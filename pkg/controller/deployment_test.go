@@ -0,0 +1,138 @@
+package controller
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/devfile/api/v2/pkg/apis/workspaces/v1alpha2"
+	"github.com/devfile/library/pkg/devfile/parser"
+	"github.com/devfile/library/pkg/devfile/parser/data"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// newDevfileObj returns a devfile containing the commands and events, without validating it
+func newDevfileObj(t *testing.T, commands []v1alpha2.Command, events v1alpha2.Events) parser.DevfileObj {
+	t.Helper()
+	devfileData, err := data.NewDevfileData(string(data.APISchemaVersion220))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = devfileData.AddCommands(commands); err != nil {
+		t.Fatal(err)
+	}
+	if err = devfileData.AddEvents(events); err != nil {
+		t.Fatal(err)
+	}
+	return parser.DevfileObj{
+		Data: devfileData,
+	}
+}
+
+func TestGetExecInitContainers(t *testing.T) {
+	probe := &corev1.Probe{
+		ProbeHandler: corev1.ProbeHandler{
+			Exec: &corev1.ExecAction{Command: []string{"true"}},
+		},
+	}
+	containers := []corev1.Container{
+		{
+			Name:           "runtime",
+			Image:          "image",
+			Ports:          []corev1.ContainerPort{{ContainerPort: 8080}},
+			LivenessProbe:  probe,
+			ReadinessProbe: probe,
+			StartupProbe:   probe,
+			Lifecycle: &corev1.Lifecycle{
+				PreStop: &corev1.LifecycleHandler{
+					Exec: &corev1.ExecAction{Command: []string{"true"}},
+				},
+			},
+		},
+		{
+			Name:  "tools",
+			Image: "tools-image",
+		},
+	}
+	longID := strings.Repeat("a", 60)
+
+	tests := []struct {
+		name     string
+		commands []v1alpha2.Command
+		preStart []string
+		first    int
+		// want are the names and images of the init containers
+		want []string
+	}{
+		{
+			name: "no preStart event",
+			commands: []v1alpha2.Command{
+				execCommand("init", "runtime"),
+			},
+		},
+		{
+			name: "commands in several containers",
+			commands: []v1alpha2.Command{
+				execCommand("init", "runtime"),
+				execCommand("install", "tools"),
+			},
+			preStart: []string{"init", "install"},
+			first:    1,
+			want:     []string{"runtime-init-2 image", "tools-install-3 tools-image"},
+		},
+		{
+			name: "composite command",
+			commands: []v1alpha2.Command{
+				execCommand("init", "runtime"),
+				execCommand("install", "tools"),
+				{
+					Id: "prepare",
+					CommandUnion: v1alpha2.CommandUnion{
+						Composite: &v1alpha2.CompositeCommand{
+							Commands: []string{"install", "init"},
+						},
+					},
+				},
+			},
+			preStart: []string{"prepare"},
+			want:     []string{"tools-install-1 tools-image", "runtime-init-2 image"},
+		},
+		{
+			name: "long name truncated",
+			commands: []v1alpha2.Command{
+				execCommand(longID, "runtime"),
+			},
+			preStart: []string{longID},
+			want:     []string{("runtime-" + longID)[:initContainerNameMaxLen] + "-1 image"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			devfileObj := newDevfileObj(t, tt.commands, v1alpha2.Events{
+				DevWorkspaceEvents: v1alpha2.DevWorkspaceEvents{
+					PreStart: tt.preStart,
+				},
+			})
+			initContainers, err := getExecInitContainers(devfileObj, containers, tt.first)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, c := range initContainers {
+				got = append(got, c.Name+" "+c.Image)
+				if !reflect.DeepEqual(c.Command, []string{"/bin/sh", "-c"}) || len(c.Args) != 1 {
+					t.Errorf("init container %q executes %v %v, want a shell command line", c.Name, c.Command, c.Args)
+				}
+				if c.Ports != nil || c.LivenessProbe != nil || c.ReadinessProbe != nil || c.StartupProbe != nil || c.Lifecycle != nil {
+					t.Errorf("init container %q has ports, probes or lifecycle handlers", c.Name)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getExecInitContainers() = %v, want %v", got, tt.want)
+			}
+			if containers[0].LivenessProbe == nil || containers[0].Ports == nil {
+				t.Errorf("getExecInitContainers() modified the containers")
+			}
+		})
+	}
+}
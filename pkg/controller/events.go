@@ -0,0 +1,116 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/devfile/api/v2/pkg/apis/workspaces/v1alpha2"
+	"github.com/devfile/library/pkg/devfile/parser"
+	"github.com/feloy/ododev/pkg/devfile"
+	"github.com/feloy/ododev/pkg/libdevfile"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// execEventCommands executes sequentially the commands of a devfile event in the pod, and sends their output to output if not nil.
+// The result of the first command failing is returned, or nil if all commands succeed
+func execEventCommands(
+	ctx context.Context,
+	client client.Client,
+	mgr manager.Manager,
+	pod *corev1.Pod,
+	syncTargets []libdevfile.SyncTarget,
+	trees []libdevfile.CommandTree,
+	output io.Writer,
+	setStatus func(status devfile.StatusContent) error,
+) (*devfile.CommandResult, error) {
+	for _, tree := range trees {
+		err := checkExecCommands(tree)
+		if err != nil {
+			return nil, err
+		}
+	}
+	runner := newCommandRunner(client, mgr, pod, syncTargets, setStatus)
+	for _, tree := range trees {
+		tail := newTailWriter(maxOutputSize)
+		var w io.Writer = tail
+		if output != nil {
+			w = io.MultiWriter(tail, output)
+		}
+		startedAt := metav1.Now()
+		err := runner.run(ctx, tree, w)
+		if err != nil {
+			result := newCommandResult(tree.Command, startedAt, tail, err)
+			if result.ExitCode < 0 {
+				// the command has not been executed
+				return nil, err
+			}
+			return result, nil
+		}
+	}
+	return nil, nil
+}
+
+// ExecStopEvents executes the preStop commands of the devfile in the pod of the component, stops the run or debug command,
+// then executes the postStop commands. The output of the commands is sent to output.
+// It is called by the client before the resources of the component are deleted, when the manager is stopped
+func ExecStopEvents(ctx context.Context, mgr manager.Manager, namespace string, componentName string, devfileObj parser.DevfileObj, output io.Writer) error {
+	events := devfileObj.Data.GetEvents()
+	preStop, err := libdevfile.GetEventCommands(devfileObj, events.PreStop)
+	if err != nil {
+		return err
+	}
+	postStop, err := libdevfile.GetEventCommands(devfileObj, events.PostStop)
+	if err != nil {
+		return err
+	}
+	if len(preStop) == 0 && len(postStop) == 0 {
+		return nil
+	}
+
+	// the cache of the manager is not usable once the manager is stopped
+	pod, err := getPod(ctx, mgr.GetAPIReader(), namespace, componentName)
+	if err != nil {
+		return err
+	}
+	syncTargets, err := libdevfile.GetSyncTargets(devfileObj)
+	if err != nil {
+		return err
+	}
+
+	result, err := execEventCommands(ctx, mgr.GetClient(), mgr, pod, syncTargets, preStop, output, nil)
+	if err != nil {
+		return err
+	}
+	if result != nil {
+		return fmt.Errorf("preStop command %q exited with code %d", result.Command, result.ExitCode)
+	}
+
+	// the debug command is executed instead of the run command in debug mode
+	for _, kind := range []v1alpha2.CommandGroupKind{v1alpha2.RunCommandGroupKind, v1alpha2.DebugCommandGroupKind} {
+		runCmd, err := libdevfile.GetDefaultCommand(devfileObj, kind)
+		if err != nil {
+			continue
+		}
+		runTree, err := libdevfile.ExpandCommand(devfileObj, runCmd)
+		if err != nil {
+			return err
+		}
+		err = stopCommands(ctx, mgr.GetClient(), mgr, pod, runTree)
+		if err != nil {
+			return err
+		}
+	}
+
+	result, err = execEventCommands(ctx, mgr.GetClient(), mgr, pod, syncTargets, postStop, output, nil)
+	if err != nil {
+		return err
+	}
+	if result != nil {
+		return fmt.Errorf("postStop command %q exited with code %d", result.Command, result.ExitCode)
+	}
+	return nil
+}
@@ -3,55 +3,130 @@ package controller
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"strings"
 
 	"github.com/devfile/api/v2/pkg/apis/workspaces/v1alpha2"
 	"github.com/feloy/ododev/pkg/container"
 	corev1 "k8s.io/api/core/v1"
+	utilexec "k8s.io/client-go/util/exec"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 )
 
+// ExecDevfileCommand executes an exec command in the container referenced by the command's component,
+// from the command's working directory (or defaultWorkingDir if not set) and with the command's env vars defined.
+// The output of the command is sent to the output of the container's main process, and to output if not nil.
+// If the command exits with a non-zero code, the error returned implements the ExitError interface
+// from "k8s.io/client-go/util/exec"
 func ExecDevfileCommand(
 	ctx context.Context,
 	client client.Client,
 	mgr manager.Manager,
 	pod *corev1.Pod,
-	containerName string,
-	targetPath string,
+	defaultWorkingDir string,
 	cmd v1alpha2.Command,
+	output io.Writer,
 ) error {
-	args := []string{"/bin/sh", "-c", fmt.Sprintf("echo $$ > /tmp/odo_command.pid; (cd %s && %s) > /proc/1/fd/1 2> /proc/1/fd/2", targetPath, cmd.Exec.CommandLine)}
-	var stdout bytes.Buffer
+	if cmd.Exec == nil {
+		return fmt.Errorf("command %q is not an exec command", cmd.Id)
+	}
+	// The exit code of the command is saved in a file, as the exit code of the pipeline is the one of tee
+	args := []string{"/bin/sh", "-c", fmt.Sprintf(`echo $$ > %s; { (%s) 2>&1; echo $? > /tmp/odo_command_$$.exit; } | tee /proc/1/fd/1; EXIT=$(cat /tmp/odo_command_$$.exit 2> /dev/null || echo 1); rm -f /tmp/odo_command_$$.exit; exit $EXIT`, getPidFile(cmd), getShellCommandLine(cmd.Exec, defaultWorkingDir))}
+	if output == nil {
+		output = io.Discard
+	}
 	var stderr bytes.Buffer
-	err := container.Exec(ctx, client, mgr, pod, containerName, args, &stdout, &stderr, nil, false)
-	fmt.Println(stdout.String())
-	fmt.Println(stderr.String())
+	err := container.Exec(ctx, client, mgr, pod, cmd.Exec.Component, args, output, &stderr, nil, false)
+	if stderr.Len() > 0 {
+		log.FromContext(ctx).Info("error output of command", "command", cmd.Id, "stderr", stderr.String())
+	}
 	return err
 }
 
+// getExitCode returns the exit code of a command executed with ExecDevfileCommand
+func getExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr utilexec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitStatus()
+	}
+	return -1
+}
+
+// getShellCommandLine returns the shell command line exporting the env vars of the command,
+// moving to the working directory and executing the command line.
+// Working directory can reference env vars, as ${PROJECT_SOURCE}, which are expanded by the shell
+func getShellCommandLine(exec *v1alpha2.ExecCommand, defaultWorkingDir string) string {
+	var parts []string
+	for _, env := range exec.Env {
+		parts = append(parts, fmt.Sprintf("export %s=%s", env.Name, shellQuote(env.Value)))
+	}
+	workingDir := exec.WorkingDir
+	if workingDir == "" {
+		workingDir = defaultWorkingDir
+	}
+	if workingDir != "" {
+		parts = append(parts, fmt.Sprintf("cd %q", workingDir))
+	}
+	parts = append(parts, exec.CommandLine)
+	return strings.Join(parts, " && ")
+}
+
+// shellQuote quotes a value to be used as a single word by the shell
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// getPidFile returns the file in the container containing the PID of the shell executing the command
+func getPidFile(cmd v1alpha2.Command) string {
+	return fmt.Sprintf("/tmp/odo_command_%s.pid", cmd.Id)
+}
+
+// StopDevfileCommand kills the processes started by an exec command executed with ExecDevfileCommand.
+// All the descendants of the shell are killed, including the processes of the command started in a sub-shell,
+// so they do not keep the resources of the command, as its ports, after it is stopped.
+// The other commands executed in the container, as a test command, are not stopped
 func StopDevfileCommand(
 	ctx context.Context,
 	client client.Client,
 	mgr manager.Manager,
 	pod *corev1.Pod,
-	containerName string,
+	cmd v1alpha2.Command,
 ) error {
+	if cmd.Exec == nil {
+		return fmt.Errorf("command %q is not an exec command", cmd.Id)
+	}
+	containerName := cmd.Exec.Component
 	args := []string{"/bin/sh", "-c", `
-ls /tmp/odo_command.pid >/dev/null 2> /dev/null && 
-(
-	PID=$(cat /tmp/odo_command.pid) && 
-	while 
-		kill -9 $(cat /proc/$PID/task/$PID/children 2> /dev/null) 2> /dev/null
+descendants() {
+	for CHILD in $(cat /proc/$1/task/*/children 2> /dev/null)
 	do
-		sleep 0.1
-	done && 
-	rm -f /tmp/odo_command.pid
-) || true`}
+		echo $CHILD
+		descendants $CHILD
+	done
+}
+PIDFILE=$1
+[ -f $PIDFILE ] || exit 0
+PID=$(cat $PIDFILE)
+while
+	PIDS=$(descendants $PID)
+	[ -n "$PIDS" ]
+do
+	kill -9 $PIDS 2> /dev/null
+	sleep 0.1
+done
+rm -f $PIDFILE`, "sh", getPidFile(cmd)}
 	var stdout bytes.Buffer
 	var stderr bytes.Buffer
 	err := container.Exec(ctx, client, mgr, pod, containerName, args, &stdout, &stderr, nil, false)
-	fmt.Println(stdout.String())
-	fmt.Println(stderr.String())
+	if stdout.Len() > 0 || stderr.Len() > 0 {
+		log.FromContext(ctx).Info("output of stop command", "container", containerName, "stdout", stdout.String(), "stderr", stderr.String())
+	}
 	return err
 }
@@ -0,0 +1,156 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/devfile/api/v2/pkg/apis/workspaces/v1alpha2"
+	"github.com/feloy/ododev/pkg/libdevfile"
+	utilexec "k8s.io/client-go/util/exec"
+)
+
+func TestGetExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{
+			name: "no error",
+			want: 0,
+		},
+		{
+			name: "command exited with a non-zero code",
+			err:  utilexec.CodeExitError{Err: errors.New("command terminated with exit code 2"), Code: 2},
+			want: 2,
+		},
+		{
+			name: "wrapped exit error",
+			err:  fmt.Errorf("executing command: %w", utilexec.CodeExitError{Err: errors.New("exit 127"), Code: 127}),
+			want: 127,
+		},
+		{
+			name: "error not related to the command",
+			err:  errors.New("connection refused"),
+			want: -1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getExitCode(tt.err); got != tt.want {
+				t.Errorf("getExitCode() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetShellCommandLine(t *testing.T) {
+	tests := []struct {
+		name              string
+		exec              v1alpha2.ExecCommand
+		defaultWorkingDir string
+		want              string
+	}{
+		{
+			name: "command line only",
+			exec: v1alpha2.ExecCommand{
+				CommandLine: "npm start",
+			},
+			want: "npm start",
+		},
+		{
+			name: "default working directory",
+			exec: v1alpha2.ExecCommand{
+				CommandLine: "npm start",
+			},
+			defaultWorkingDir: "/projects",
+			want:              `cd "/projects" && npm start`,
+		},
+		{
+			name: "working directory of the command",
+			exec: v1alpha2.ExecCommand{
+				CommandLine: "npm start",
+				WorkingDir:  "${PROJECT_SOURCE}/app",
+			},
+			defaultWorkingDir: "/projects",
+			want:              `cd "${PROJECT_SOURCE}/app" && npm start`,
+		},
+		{
+			name: "env vars",
+			exec: v1alpha2.ExecCommand{
+				CommandLine: "npm start",
+				Env: []v1alpha2.EnvVar{
+					{Name: "PORT", Value: "3000"},
+					{Name: "MESSAGE", Value: "it's $HOME"},
+				},
+			},
+			defaultWorkingDir: "/projects",
+			want:              `export PORT='3000' && export MESSAGE='it'\''s $HOME' && cd "/projects" && npm start`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getShellCommandLine(&tt.exec, tt.defaultWorkingDir); got != tt.want {
+				t.Errorf("getShellCommandLine() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetWorkingDir(t *testing.T) {
+	syncTargets := []libdevfile.SyncTarget{
+		{ContainerName: "runtime", Path: "/projects"},
+		{ContainerName: "tools", Path: "/tools"},
+	}
+	tests := []struct {
+		name string
+		cmd  v1alpha2.Command
+		want string
+	}{
+		{
+			name: "command in the first container",
+			cmd:  execCommand("run", "runtime"),
+			want: "/projects",
+		},
+		{
+			name: "command in another container",
+			cmd:  execCommand("run", "tools"),
+			want: "/tools",
+		},
+		{
+			name: "command in a container not mounting sources",
+			cmd:  execCommand("run", "db"),
+			want: "",
+		},
+		{
+			name: "not an exec command",
+			cmd: v1alpha2.Command{
+				Id: "apply",
+				CommandUnion: v1alpha2.CommandUnion{
+					Apply: &v1alpha2.ApplyCommand{Component: "image"},
+				},
+			},
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getWorkingDir(syncTargets, tt.cmd); got != tt.want {
+				t.Errorf("getWorkingDir() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func execCommand(id string, component string) v1alpha2.Command {
+	return v1alpha2.Command{
+		Id: id,
+		CommandUnion: v1alpha2.CommandUnion{
+			Exec: &v1alpha2.ExecCommand{
+				CommandLine: "echo " + id,
+				Component:   component,
+			},
+		},
+	}
+}
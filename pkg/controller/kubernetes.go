@@ -2,52 +2,148 @@ package controller
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/devfile/api/v2/pkg/apis/workspaces/v1alpha2"
+	"github.com/feloy/ododev/pkg/devfile"
+	"github.com/feloy/ododev/pkg/libdevfile"
 	"github.com/ghodss/yaml"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
-func pushKubernetesComponents(ctx context.Context, client client.Client, components []v1alpha2.Component, namespace string, ownerRef metav1.OwnerReference) error {
+// pushKubernetesComponents applies the resources defined by the Kubernetes components,
+// and returns the inventory of these resources and the field conflicts detected while applying them.
+// manifests contains the manifests of the components referenced by URI, indexed by component name,
+// in which the devfile variables are substituted
+func pushKubernetesComponents(ctx context.Context, cli client.Client, components []v1alpha2.Component, manifests map[string]string, variables map[string]string, namespace string, componentName string, ownerRef metav1.OwnerReference) ([]devfile.KubernetesObject, []string, error) {
+	log := log.FromContext(ctx)
 
-	// create an object on the kubernetes cluster for all the Kubernetes Inlined components
+	inventory := make([]devfile.KubernetesObject, 0, len(components))
+	conflicts := make([]string, 0)
+
+	// apply an object on the kubernetes cluster for all the Kubernetes components
 	for _, c := range components {
-		yml := c.Kubernetes.Inlined // TODO call GetK8sManifestWithVariablesSubstituted
+		// variables are already substituted in inlined manifests when parsing the devfile
+		yml := c.Kubernetes.Inlined
+		if c.Kubernetes.Uri != "" {
+			manifest, ok := manifests[c.Name]
+			if !ok {
+				return nil, nil, fmt.Errorf("manifest of component %q not found in spec", c.Name)
+			}
+			var warnings []string
+			yml, warnings = libdevfile.GetK8sManifestWithVariablesSubstituted(manifest, variables)
+			if len(warnings) > 0 {
+				log.Info("undefined variables", "component", c.Name, "variables", warnings)
+			}
+		}
 		var u unstructured.Unstructured
 		err := yaml.Unmarshal([]byte(yml), &u.Object)
 		if err != nil {
-			return err
+			return nil, nil, err
 		}
 		u.SetNamespace(namespace)
+		labels := u.GetLabels()
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		labels[devfile.ComponentLabel] = componentName
+		u.SetLabels(labels)
+		u.SetOwnerReferences(append(u.GetOwnerReferences(), ownerRef))
+		// status is not applied
+		unstructured.RemoveNestedField(u.Object, "status")
+
+		inventory = append(inventory, devfile.KubernetesObject{
+			APIVersion: u.GetAPIVersion(),
+			Kind:       u.GetKind(),
+			Name:       u.GetName(),
+		})
+
+		err = cli.Patch(ctx, &u, client.Apply, client.FieldOwner("ododev"))
+		if err != nil {
+			if errors.IsConflict(err) {
+				conflicts = append(conflicts, fmt.Sprintf("%s %s: %s", u.GetKind(), u.GetName(), err))
+				continue
+			}
+			return nil, nil, err
+		}
+	}
+
+	return inventory, conflicts, nil
+}
+
+// pruneKubernetesComponents deletes the resources present in the previous inventory
+// but not in the current one. Only resources labeled as created for the component are deleted
+func pruneKubernetesComponents(ctx context.Context, cli client.Client, previous []devfile.KubernetesObject, current []devfile.KubernetesObject, namespace string, componentName string) error {
+	kept := make(map[devfile.KubernetesObject]struct{}, len(current))
+	for _, obj := range current {
+		kept[obj] = struct{}{}
+	}
+
+	for _, obj := range previous {
+		if _, ok := kept[obj]; ok {
+			continue
+		}
 
-		var prev unstructured.Unstructured
-		prev.SetKind(u.GetKind())
-		prev.SetAPIVersion(u.GetAPIVersion())
-		err = client.Get(ctx, types.NamespacedName{
-			Name:      u.GetName(),
+		var u unstructured.Unstructured
+		u.SetAPIVersion(obj.APIVersion)
+		u.SetKind(obj.Kind)
+		err := cli.Get(ctx, types.NamespacedName{
+			Name:      obj.Name,
 			Namespace: namespace,
-		}, &prev)
+		}, &u)
 		if err != nil {
 			if errors.IsNotFound(err) {
-				u.SetOwnerReferences(append(u.GetOwnerReferences(), ownerRef))
-				err = client.Create(ctx, &u)
-				if err != nil {
-					return err
-				}
-			} else {
-				return err
+				continue
 			}
+			return err
 		}
 
-		// TODO patch it
+		if u.GetLabels()[devfile.ComponentLabel] != componentName {
+			// the resource has not been created by this component, do not touch it
+			continue
+		}
 
+		err = cli.Delete(ctx, &u)
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
 	}
+	return nil
+}
 
-	// TODO delete components removed from devfile
-
+// watchKubernetesComponents watches the kinds of the objects of the inventory not watched yet,
+// so a modification or deletion of these objects triggers a reconcile of the spec of the component
+func (r *ReconcileConfigmap) watchKubernetesComponents(inventory []devfile.KubernetesObject, namespace string, componentName string) error {
+	if r.Controller == nil {
+		return nil
+	}
+	if r.watchedKinds == nil {
+		r.watchedKinds = map[schema.GroupVersionKind]bool{}
+	}
+	componentPredicate := predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return obj.GetLabels()[devfile.ComponentLabel] == componentName
+	})
+	for _, obj := range inventory {
+		gvk := schema.FromAPIVersionAndKind(obj.APIVersion, obj.Kind)
+		if r.watchedKinds[gvk] {
+			continue
+		}
+		var u unstructured.Unstructured
+		u.SetGroupVersionKind(gvk)
+		err := r.Controller.Watch(&source.Kind{Type: &u}, enqueueComponentSpec(namespace, componentName),
+			componentPredicate, predicate.GenerationChangedPredicate{})
+		if err != nil {
+			return err
+		}
+		r.watchedKinds[gvk] = true
+	}
 	return nil
 }
@@ -0,0 +1,124 @@
+package controller
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/feloy/ododev/pkg/devfile"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// configMapObject returns the inventory entry of a configmap
+func configMapObject(name string) devfile.KubernetesObject {
+	return devfile.KubernetesObject{
+		APIVersion: "v1",
+		Kind:       "ConfigMap",
+		Name:       name,
+	}
+}
+
+// newConfigMap returns a configmap in the namespace "ns", created for the component if not empty
+func newConfigMap(name string, componentName string) *corev1.ConfigMap {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "ns",
+		},
+	}
+	if componentName != "" {
+		cm.SetLabels(map[string]string{devfile.ComponentLabel: componentName})
+	}
+	return cm
+}
+
+func TestPruneKubernetesComponents(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing []client.Object
+		previous []devfile.KubernetesObject
+		current  []devfile.KubernetesObject
+		// want are the names of the configmaps existing after the prune
+		want []string
+	}{
+		{
+			name: "no previous inventory",
+			existing: []client.Object{
+				newConfigMap("a", "my-component"),
+			},
+			current: []devfile.KubernetesObject{configMapObject("a")},
+			want:    []string{"a"},
+		},
+		{
+			name: "component removed from the devfile",
+			existing: []client.Object{
+				newConfigMap("a", "my-component"),
+				newConfigMap("b", "my-component"),
+			},
+			previous: []devfile.KubernetesObject{configMapObject("a"), configMapObject("b")},
+			current:  []devfile.KubernetesObject{configMapObject("a")},
+			want:     []string{"a"},
+		},
+		{
+			name: "all components removed from the devfile",
+			existing: []client.Object{
+				newConfigMap("a", "my-component"),
+				newConfigMap("b", "my-component"),
+			},
+			previous: []devfile.KubernetesObject{configMapObject("a"), configMapObject("b")},
+			want:     []string{},
+		},
+		{
+			name: "removed resource already deleted",
+			existing: []client.Object{
+				newConfigMap("a", "my-component"),
+			},
+			previous: []devfile.KubernetesObject{configMapObject("a"), configMapObject("b")},
+			current:  []devfile.KubernetesObject{configMapObject("a")},
+			want:     []string{"a"},
+		},
+		{
+			name: "removed resource created by another component",
+			existing: []client.Object{
+				newConfigMap("a", "other"),
+			},
+			previous: []devfile.KubernetesObject{configMapObject("a")},
+			want:     []string{"a"},
+		},
+		{
+			name: "removed resource not created by ododev",
+			existing: []client.Object{
+				newConfigMap("a", ""),
+			},
+			previous: []devfile.KubernetesObject{configMapObject("a")},
+			want:     []string{"a"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			cli := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(tt.existing...).Build()
+			err := pruneKubernetesComponents(ctx, cli, tt.previous, tt.current, "ns", "my-component")
+			if err != nil {
+				t.Fatal(err)
+			}
+			var list corev1.ConfigMapList
+			if err = cli.List(ctx, &list, client.InNamespace("ns")); err != nil {
+				t.Fatal(err)
+			}
+			got := []string{}
+			for _, cm := range list.Items {
+				got = append(got, cm.GetName())
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("configmaps after prune = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
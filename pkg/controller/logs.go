@@ -0,0 +1,245 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/feloy/ododev/pkg/container"
+)
+
+const (
+	// logsPollInterval is the interval at which the pod of the component is checked, to follow the logs of a new pod
+	logsPollInterval = 5 * time.Second
+	// logsRetryDelay is the delay before following again the logs of a container, after its logs stream ended
+	logsRetryDelay = 2 * time.Second
+)
+
+// logsColors are the colors of the prefixes of the lines, chosen depending on the name of the container
+var logsColors = []string{"\x1b[36m", "\x1b[33m", "\x1b[32m", "\x1b[35m", "\x1b[34m", "\x1b[31m"}
+
+const colorReset = "\x1b[0m"
+
+// LogsOptions defines the logs to display
+type LogsOptions struct {
+	// Follow is true to follow the logs until the context is done
+	Follow bool
+	// Since limits the logs to the ones more recent than this duration, all the logs are displayed if zero
+	Since time.Duration
+	// Containers are the containers of which the logs are displayed, all the containers of the pod if empty
+	Containers []string
+	// Color is true to color the prefixes of the lines
+	Color bool
+}
+
+// FollowLogs displays the logs of the containers of the pod of the component, each line being prefixed with the name of the container.
+// When options.Follow is set, the logs are followed until the context is done, and are followed again when a container restarts
+// or the pod is replaced. The manager does not need to be started
+func FollowLogs(ctx context.Context, mgr manager.Manager, namespace string, componentName string, options LogsOptions, out io.Writer) error {
+	w := &logsWriter{
+		out:   out,
+		color: options.Color,
+	}
+
+	if !options.Follow {
+		pod, err := getPod(ctx, mgr.GetAPIReader(), namespace, componentName)
+		if err != nil {
+			return err
+		}
+		containers, err := getLogsContainers(pod, options.Containers)
+		if err != nil {
+			return err
+		}
+		for _, containerName := range containers {
+			prefixed := w.forContainer(containerName)
+			err = container.StreamLogs(ctx, mgr, pod, getLogOptions(containerName, options.Since, false), prefixed)
+			prefixed.flush()
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var (
+		podUID types.UID
+		stop   = func() {}
+		since  = options.Since
+	)
+	defer func() {
+		stop()
+	}()
+	for {
+		pod, err := getPod(ctx, mgr.GetAPIReader(), namespace, componentName)
+		if err == nil && pod.GetUID() != podUID {
+			containers, err := getLogsContainers(pod, options.Containers)
+			if err != nil {
+				return err
+			}
+			stop()
+			podUID = pod.GetUID()
+			stop = followPodLogs(ctx, mgr, pod, containers, since, w)
+			// the logs of the next pods are displayed from their start
+			since = 0
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(logsPollInterval):
+		}
+	}
+}
+
+// followPodLogs follows the logs of the containers of the pod, until the returned function is called
+func followPodLogs(ctx context.Context, mgr manager.Manager, pod *corev1.Pod, containers []string, since time.Duration, w *logsWriter) func() {
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	for _, containerName := range containers {
+		wg.Add(1)
+		go func(containerName string) {
+			defer wg.Done()
+			followContainerLogs(ctx, mgr, pod, containerName, since, w.forContainer(containerName))
+		}(containerName)
+	}
+	return func() {
+		cancel()
+		wg.Wait()
+	}
+}
+
+// followContainerLogs follows the logs of a container of the pod until the context is done.
+// When the container terminates, the logs of the next execution of the container are followed
+func followContainerLogs(ctx context.Context, mgr manager.Manager, pod *corev1.Pod, containerName string, since time.Duration, out *prefixWriter) {
+	log := log.FromContext(ctx).WithValues("pod", pod.GetName(), "container", containerName)
+
+	opts := getLogOptions(containerName, since, true)
+	for {
+		err := container.StreamLogs(ctx, mgr, pod, opts, out)
+		out.flush()
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			log.Info("unable to follow logs", "err", err)
+		}
+
+		// the container terminated, or is not started yet
+		now := metav1.Now()
+		opts.SinceSeconds = nil
+		opts.SinceTime = &now
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(logsRetryDelay):
+		}
+	}
+}
+
+func getLogOptions(containerName string, since time.Duration, follow bool) *corev1.PodLogOptions {
+	opts := &corev1.PodLogOptions{
+		Container: containerName,
+		Follow:    follow,
+	}
+	if since > 0 {
+		seconds := int64(since.Seconds())
+		if seconds < 1 {
+			seconds = 1
+		}
+		opts.SinceSeconds = &seconds
+	}
+	return opts
+}
+
+// getLogsContainers returns the containers of the pod of which the logs are displayed
+func getLogsContainers(pod *corev1.Pod, names []string) ([]string, error) {
+	var all []string
+	for _, c := range pod.Spec.Containers {
+		all = append(all, c.Name)
+	}
+	if len(names) == 0 {
+		return all, nil
+	}
+	for _, name := range names {
+		found := false
+		for _, c := range all {
+			if c == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("container %q not found in pod %q, available containers: %v", name, pod.GetName(), all)
+		}
+	}
+	return names, nil
+}
+
+// logsWriter writes lines of logs to out, without mixing the lines of several containers
+type logsWriter struct {
+	mu    sync.Mutex
+	out   io.Writer
+	color bool
+}
+
+// forContainer returns a writer prefixing each line with the name of the container
+func (o *logsWriter) forContainer(containerName string) *prefixWriter {
+	prefix := "[" + containerName + "] "
+	if o.color {
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(containerName))
+		prefix = logsColors[h.Sum32()%uint32(len(logsColors))] + prefix + colorReset
+	}
+	return &prefixWriter{
+		logs:   o,
+		prefix: []byte(prefix),
+	}
+}
+
+func (o *logsWriter) writeLine(prefix []byte, line []byte) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	_, _ = o.out.Write(prefix)
+	_, _ = o.out.Write(line)
+}
+
+// prefixWriter writes the complete lines written to it, prefixed
+type prefixWriter struct {
+	logs   *logsWriter
+	prefix []byte
+	// partial is the beginning of a line not terminated yet
+	partial []byte
+}
+
+func (o *prefixWriter) Write(p []byte) (int, error) {
+	o.partial = append(o.partial, p...)
+	for {
+		i := bytes.IndexByte(o.partial, '\n')
+		if i < 0 {
+			break
+		}
+		o.logs.writeLine(o.prefix, o.partial[:i+1])
+		o.partial = o.partial[i+1:]
+	}
+	return len(p), nil
+}
+
+// flush writes the line not terminated yet
+func (o *prefixWriter) flush() {
+	if len(o.partial) == 0 {
+		return
+	}
+	o.logs.writeLine(o.prefix, append(o.partial, '\n'))
+	o.partial = nil
+}
@@ -0,0 +1,138 @@
+package controller
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestGetLogOptions(t *testing.T) {
+	tests := []struct {
+		name  string
+		since time.Duration
+		want  *int64
+	}{
+		{name: "all logs", since: 0, want: nil},
+		{name: "seconds", since: 90 * time.Second, want: pointer.Int64(90)},
+		{name: "less than a second", since: 100 * time.Millisecond, want: pointer.Int64(1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := getLogOptions("runtime", tt.since, true)
+			if got.Container != "runtime" || !got.Follow {
+				t.Errorf("getLogOptions() = %+v, want options following the logs of container runtime", got)
+			}
+			if !reflect.DeepEqual(got.SinceSeconds, tt.want) {
+				t.Errorf("getLogOptions() since = %v, want %v", pointer.Int64Deref(got.SinceSeconds, 0), pointer.Int64Deref(tt.want, 0))
+			}
+		})
+	}
+}
+
+func TestGetLogsContainers(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod"},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "runtime"}, {Name: "tools"}},
+		},
+	}
+	tests := []struct {
+		name    string
+		names   []string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "all containers",
+			want: []string{"runtime", "tools"},
+		},
+		{
+			name:  "selected containers",
+			names: []string{"tools"},
+			want:  []string{"tools"},
+		},
+		{
+			name:    "unknown container",
+			names:   []string{"runtime", "db"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getLogsContainers(pod, tt.names)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getLogsContainers() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getLogsContainers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrefixWriter(t *testing.T) {
+	tests := []struct {
+		name   string
+		writes []string
+		flush  bool
+		want   string
+	}{
+		{
+			name:   "complete lines",
+			writes: []string{"first\nsecond\n"},
+			want:   "[runtime] first\n[runtime] second\n",
+		},
+		{
+			name:   "line written in several parts",
+			writes: []string{"fir", "st\nsec", "ond\n"},
+			want:   "[runtime] first\n[runtime] second\n",
+		},
+		{
+			name:   "line not terminated",
+			writes: []string{"first\nsecond"},
+			want:   "[runtime] first\n",
+		},
+		{
+			name:   "line not terminated flushed",
+			writes: []string{"first\nsecond"},
+			flush:  true,
+			want:   "[runtime] first\n[runtime] second\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			w := (&logsWriter{out: &out}).forContainer("runtime")
+			for _, s := range tt.writes {
+				n, err := w.Write([]byte(s))
+				if err != nil || n != len(s) {
+					t.Fatalf("Write() = %d, %v, want %d, nil", n, err, len(s))
+				}
+			}
+			if tt.flush {
+				w.flush()
+			}
+			if got := out.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogsWriterColor(t *testing.T) {
+	var out bytes.Buffer
+	w := (&logsWriter{out: &out, color: true}).forContainer("runtime")
+	if _, err := w.Write([]byte("line\n")); err != nil {
+		t.Fatal(err)
+	}
+	got := out.String()
+	if !strings.Contains(got, "[runtime] "+colorReset+"line\n") || !strings.HasPrefix(got, "\x1b[") {
+		t.Errorf("output = %q, want a colored prefix", got)
+	}
+}
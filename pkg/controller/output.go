@@ -0,0 +1,44 @@
+package controller
+
+import (
+	"bytes"
+	"sync"
+)
+
+// maxOutputSize is the maximum size of the output of a command kept in the status
+const maxOutputSize = 4096
+
+// tailWriter is a writer keeping only the last bytes written, at most max bytes
+type tailWriter struct {
+	mu  sync.Mutex
+	max int
+	buf []byte
+}
+
+func newTailWriter(max int) *tailWriter {
+	return &tailWriter{
+		max: max,
+	}
+}
+
+func (o *tailWriter) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.buf = append(o.buf, p...)
+	if len(o.buf) > o.max {
+		o.buf = o.buf[len(o.buf)-o.max:]
+	}
+	return len(p), nil
+}
+
+// String returns the bytes kept, starting at the beginning of a line if the output has been truncated
+func (o *tailWriter) String() string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if len(o.buf) == o.max {
+		if i := bytes.IndexByte(o.buf, '\n'); i >= 0 {
+			return string(o.buf[i+1:])
+		}
+	}
+	return string(o.buf)
+}
@@ -0,0 +1,61 @@
+package controller
+
+import (
+	"testing"
+)
+
+func TestTailWriter(t *testing.T) {
+	tests := []struct {
+		name   string
+		max    int
+		writes []string
+		want   string
+	}{
+		{
+			name:   "output shorter than max",
+			max:    20,
+			writes: []string{"line 1\n", "line 2\n"},
+			want:   "line 1\nline 2\n",
+		},
+		{
+			name:   "output truncated at the beginning of a line",
+			max:    10,
+			writes: []string{"line 1\n", "line 2\n", "line 3\n"},
+			want:   "line 3\n",
+		},
+		{
+			name:   "output truncated without newline",
+			max:    4,
+			writes: []string{"abcdefgh"},
+			want:   "efgh",
+		},
+		{
+			name:   "large write",
+			max:    9,
+			writes: []string{"line 1\nline 2\nline 3\n"},
+			want:   "line 3\n",
+		},
+		{
+			name: "no output",
+			max:  10,
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newTailWriter(tt.max)
+			for _, s := range tt.writes {
+				n, err := w.Write([]byte(s))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if n != len(s) {
+					t.Errorf("Write() = %d, want %d", n, len(s))
+				}
+			}
+			if got := w.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -4,17 +4,45 @@ import (
 	"context"
 	"fmt"
 
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	pkgclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-func getPod(ctx context.Context, client pkgclient.Client, namespace string, componentName string) (*corev1.Pod, error) {
+const (
+	// podPhaseField is the field indexed to list the pods by phase
+	podPhaseField = "status.phase"
+	// revisionAnnotation is the annotation containing the revision of a deployment and of its replicasets
+	revisionAnnotation = "deployment.kubernetes.io/revision"
+)
+
+// indexPodPhase returns the phase of a pod, to be indexed as podPhaseField
+func indexPodPhase(obj pkgclient.Object) []string {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return nil
+	}
+	return []string{string(pod.Status.Phase)}
+}
+
+// getPod returns the running and ready pod of the current replicaset of the component's deployment.
+// Pods of previous replicasets, being replaced during a rollout, and terminating pods are ignored
+func getPod(ctx context.Context, client pkgclient.Reader, namespace string, componentName string) (*corev1.Pod, error) {
+	podTemplateHash, err := getCurrentPodTemplateHash(ctx, client, namespace, componentName)
+	if err != nil {
+		return nil, err
+	}
 
 	var list corev1.PodList
-	err := client.List(ctx, &list,
+	err = client.List(ctx, &list,
 		pkgclient.InNamespace(namespace),
-		pkgclient.MatchingLabels{"component": componentName},
-		// pkgclient.MatchingFields{"status.phase": "Running"}, // TODO get error "Index with name field:status.phase does not exist"
+		pkgclient.MatchingLabels{
+			"component":                            componentName,
+			appsv1.DefaultDeploymentUniqueLabelKey: podTemplateHash,
+		},
+		pkgclient.MatchingFields{podPhaseField: string(corev1.PodRunning)},
 	)
 	if err != nil {
 		return nil, err
@@ -23,13 +51,65 @@ func getPod(ctx context.Context, client pkgclient.Client, namespace string, comp
 	count := 0
 	found := -1
 	for i, pod := range list.Items {
-		if pod.Status.Phase == "Running" {
-			count++
-			found = i
+		if pod.GetDeletionTimestamp() != nil || !isPodReady(&pod) {
+			continue
 		}
+		count++
+		found = i
 	}
 	if count != 1 {
 		return nil, fmt.Errorf("%d pods found", count)
 	}
 	return &list.Items[found], nil
 }
+
+// getCurrentPodTemplateHash returns the pod-template-hash of the replicaset
+// having the same revision as the deployment of the component
+func getCurrentPodTemplateHash(ctx context.Context, client pkgclient.Reader, namespace string, componentName string) (string, error) {
+	var dep appsv1.Deployment
+	err := client.Get(ctx, types.NamespacedName{
+		Namespace: namespace,
+		Name:      getDeploymentName(componentName),
+	}, &dep)
+	if err != nil {
+		return "", err
+	}
+	revision := dep.GetAnnotations()[revisionAnnotation]
+
+	var list appsv1.ReplicaSetList
+	err = client.List(ctx, &list,
+		pkgclient.InNamespace(namespace),
+		pkgclient.MatchingLabels{"component": componentName},
+	)
+	if err != nil {
+		return "", err
+	}
+	for _, rs := range list.Items {
+		if !isOwnedBy(rs.GetOwnerReferences(), dep.GetUID()) {
+			continue
+		}
+		if rs.GetAnnotations()[revisionAnnotation] == revision {
+			return rs.GetLabels()[appsv1.DefaultDeploymentUniqueLabelKey], nil
+		}
+	}
+	return "", fmt.Errorf("no replicaset found for revision %q of deployment %q", revision, dep.GetName())
+}
+
+func isOwnedBy(ownerRefs []metav1.OwnerReference, uid types.UID) bool {
+	for _, ref := range ownerRefs {
+		if ref.UID == uid {
+			return true
+		}
+	}
+	return false
+}
+
+// isPodReady returns true if the Ready condition of the pod is true
+func isPodReady(pod *corev1.Pod) bool {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,207 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/feloy/ododev/pkg/devfile"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// newPod returns a running pod of the component in the namespace "ns", created by the replicaset with the pod-template-hash
+func newPod(name string, podTemplateHash string, ready bool) *corev1.Pod {
+	readyStatus := corev1.ConditionFalse
+	if ready {
+		readyStatus = corev1.ConditionTrue
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "ns",
+			Labels: map[string]string{
+				"component":                            "my-component",
+				appsv1.DefaultDeploymentUniqueLabelKey: podTemplateHash,
+			},
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			Conditions: []corev1.PodCondition{
+				{Type: corev1.PodReady, Status: readyStatus},
+			},
+		},
+	}
+}
+
+// newReplicaSet returns a replicaset of the deployment of the component in the namespace "ns"
+func newReplicaSet(podTemplateHash string, revision string, owner types.UID) *appsv1.ReplicaSet {
+	return &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-component-app-" + podTemplateHash,
+			Namespace: "ns",
+			Labels: map[string]string{
+				"component":                            "my-component",
+				appsv1.DefaultDeploymentUniqueLabelKey: podTemplateHash,
+			},
+			Annotations:     map[string]string{revisionAnnotation: revision},
+			OwnerReferences: []metav1.OwnerReference{{UID: owner}},
+		},
+	}
+}
+
+func TestIsPodReady(t *testing.T) {
+	tests := []struct {
+		name       string
+		conditions []corev1.PodCondition
+		want       bool
+	}{
+		{
+			name: "no condition",
+			want: false,
+		},
+		{
+			name: "ready",
+			conditions: []corev1.PodCondition{
+				{Type: corev1.PodScheduled, Status: corev1.ConditionTrue},
+				{Type: corev1.PodReady, Status: corev1.ConditionTrue},
+			},
+			want: true,
+		},
+		{
+			name: "not ready",
+			conditions: []corev1.PodCondition{
+				{Type: corev1.PodScheduled, Status: corev1.ConditionTrue},
+				{Type: corev1.PodReady, Status: corev1.ConditionFalse},
+			},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := corev1.Pod{
+				Status: corev1.PodStatus{Conditions: tt.conditions},
+			}
+			if got := isPodReady(&pod); got != tt.want {
+				t.Errorf("isPodReady() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetPod(t *testing.T) {
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        getDeploymentName("my-component"),
+			Namespace:   "ns",
+			UID:         "deployment-uid",
+			Annotations: map[string]string{revisionAnnotation: "2"},
+		},
+	}
+	terminating := newPod("terminating", "current", true)
+	now := metav1.Now()
+	terminating.SetDeletionTimestamp(&now)
+
+	tests := []struct {
+		name     string
+		existing []client.Object
+		want     string
+		wantErr  bool
+	}{
+		{
+			name: "pod of the current replicaset",
+			existing: []client.Object{
+				newReplicaSet("previous", "1", "deployment-uid"),
+				newReplicaSet("current", "2", "deployment-uid"),
+				newPod("previous-pod", "previous", true),
+				newPod("current-pod", "current", true),
+			},
+			want: "current-pod",
+		},
+		{
+			name: "replicaset owned by another deployment",
+			existing: []client.Object{
+				newReplicaSet("current", "2", "other-uid"),
+				newPod("current-pod", "current", true),
+			},
+			wantErr: true,
+		},
+		{
+			name: "pod not ready",
+			existing: []client.Object{
+				newReplicaSet("current", "2", "deployment-uid"),
+				newPod("current-pod", "current", false),
+			},
+			wantErr: true,
+		},
+		{
+			name: "terminating pod ignored",
+			existing: []client.Object{
+				newReplicaSet("current", "2", "deployment-uid"),
+				terminating,
+				newPod("current-pod", "current", true),
+			},
+			want: "current-pod",
+		},
+		{
+			name: "several ready pods",
+			existing: []client.Object{
+				newReplicaSet("current", "2", "deployment-uid"),
+				newPod("pod-1", "current", true),
+				newPod("pod-2", "current", true),
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(deployment).WithObjects(tt.existing...).Build()
+			pod, err := getPod(context.Background(), cli, "ns", "my-component")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getPod() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if pod.GetName() != tt.want {
+				t.Errorf("getPod() = %q, want %q", pod.GetName(), tt.want)
+			}
+		})
+	}
+}
+
+func TestGetSyncedPod(t *testing.T) {
+	tests := []struct {
+		name              string
+		containerStatuses []corev1.ContainerStatus
+		want              devfile.SyncedPod
+	}{
+		{
+			name: "no container status",
+			want: devfile.SyncedPod{UID: "pod-uid"},
+		},
+		{
+			name: "restarts of all the containers",
+			containerStatuses: []corev1.ContainerStatus{
+				{Name: "runtime", RestartCount: 2},
+				{Name: "tools", RestartCount: 1},
+			},
+			want: devfile.SyncedPod{UID: "pod-uid", RestartCount: 3},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{UID: "pod-uid"},
+				Status:     corev1.PodStatus{ContainerStatuses: tt.containerStatuses},
+			}
+			if got := getSyncedPod(&pod); got != tt.want {
+				t.Errorf("getSyncedPod() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
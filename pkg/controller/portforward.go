@@ -0,0 +1,227 @@
+package controller
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/feloy/ododev/pkg/container"
+	"github.com/feloy/ododev/pkg/devfile"
+	"github.com/feloy/ododev/pkg/libdevfile"
+)
+
+const (
+	// reconnectInitialDelay is the delay before the first attempt to forward a port again after a failure
+	reconnectInitialDelay = 1 * time.Second
+	// reconnectMaxDelay is the maximum delay before forwarding a port again
+	reconnectMaxDelay = 30 * time.Second
+	// reconnectResetDuration is the duration after which a connection is considered as stable,
+	// the delay before the next attempt is reset to reconnectInitialDelay
+	reconnectResetDuration = 1 * time.Minute
+)
+
+// portForwarder forwards the ports of the containers of the dev pod to local ports.
+// Each port is forwarded independently, and is forwarded again with an exponential backoff
+// when the connection to the pod is lost, to the current pod of the component
+type portForwarder struct {
+	mgr           manager.Manager
+	namespace     string
+	componentName string
+
+	mu sync.Mutex
+	// generation is incremented every time the ports are forwarded or stopped,
+	// so the goroutines of a previous forwarding do not report their state
+	generation int64
+	cancel     context.CancelFunc
+	wg         sync.WaitGroup
+	podUID     types.UID
+	// assigned are the local ports of the endpoints of the last forwarding, indexed by endpoint name
+	assigned map[string]int
+	// ports is the state of the forwarded ports
+	ports     []devfile.ForwardedPort
+	setStatus func(status devfile.StatusContent) error
+	// reportSeq is incremented every time the state of the ports changes
+	reportSeq int64
+
+	// reportMu serializes the reports, written without holding mu.
+	// reportedSeq is the sequence number of the last report written, so an older state is not reported after a newer one
+	reportMu    sync.Mutex
+	reportedSeq int64
+}
+
+func newPortForwarder(mgr manager.Manager, namespace string, componentName string) *portForwarder {
+	return &portForwarder{
+		mgr:           mgr,
+		namespace:     namespace,
+		componentName: componentName,
+	}
+}
+
+// isForwarding returns true if the ports are forwarded to the pod
+func (o *portForwarder) isForwarding(podUID types.UID) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.cancel != nil && o.podUID == podUID
+}
+
+// assignedPorts returns the local ports of the endpoints of the last forwarding, indexed by endpoint name,
+// overriding the ports of previous
+func (o *portForwarder) assignedPorts(previous map[string]int) map[string]int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	result := make(map[string]int, len(previous)+len(o.assigned))
+	for name, port := range previous {
+		result[name] = port
+	}
+	for name, port := range o.assigned {
+		result[name] = port
+	}
+	return result
+}
+
+// start forwards the ports of the pod, until stop is called.
+// The state of the ports is reported with setStatus
+func (o *portForwarder) start(ctx context.Context, pod *corev1.Pod, pairs []libdevfile.PortPair, setStatus func(status devfile.StatusContent) error) {
+	o.mu.Lock()
+
+	ctx, o.cancel = context.WithCancel(ctx)
+	o.generation++
+	o.podUID = pod.GetUID()
+	o.setStatus = setStatus
+	o.assigned = make(map[string]int, len(pairs))
+	o.ports = make([]devfile.ForwardedPort, 0, len(pairs))
+	for _, pair := range pairs {
+		o.assigned[pair.EndpointName] = pair.LocalPort
+		o.ports = append(o.ports, devfile.ForwardedPort{
+			ContainerName: pair.ContainerName,
+			EndpointName:  pair.EndpointName,
+			LocalPort:     pair.LocalPort,
+			ContainerPort: pair.ContainerPort,
+			State:         devfile.PortForwardConnecting,
+		})
+	}
+	report := o.snapshotLocked()
+	for i, pair := range pairs {
+		o.wg.Add(1)
+		go func(i int, pair libdevfile.PortPair, generation int64) {
+			defer o.wg.Done()
+			o.forward(ctx, pod, i, pair, generation)
+		}(i, pair, o.generation)
+	}
+	o.mu.Unlock()
+
+	report()
+}
+
+// stop stops forwarding the ports, and waits until the local ports are released
+func (o *portForwarder) stop() {
+	o.mu.Lock()
+	if o.cancel != nil {
+		o.cancel()
+		o.cancel = nil
+	}
+	o.generation++
+	o.ports = nil
+	o.mu.Unlock()
+
+	o.wg.Wait()
+}
+
+// forward forwards a port, to the current pod of the component, until the context is done
+func (o *portForwarder) forward(ctx context.Context, pod *corev1.Pod, index int, pair libdevfile.PortPair, generation int64) {
+	log := log.FromContext(ctx).WithValues("endpoint", pair.EndpointName, "port", pair.String())
+
+	delay := reconnectInitialDelay
+	for {
+		stopChan := make(chan struct{})
+		done := make(chan struct{})
+		go func() {
+			select {
+			case <-ctx.Done():
+				close(stopChan)
+			case <-done:
+			}
+		}()
+
+		var readyAt time.Time
+		err := container.ForwardPort(o.mgr, pod, pair.String(), stopChan, func() {
+			readyAt = time.Now()
+			o.setState(generation, index, devfile.PortForwardListening, nil)
+		}, nil, nil)
+		close(done)
+		if ctx.Err() != nil {
+			return
+		}
+
+		if !readyAt.IsZero() && time.Since(readyAt) > reconnectResetDuration {
+			delay = reconnectInitialDelay
+		}
+		log.Info("port forwarding failed", "err", err, "delay", delay)
+		o.setState(generation, index, devfile.PortForwardFailed, err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay *= 2
+		if delay > reconnectMaxDelay {
+			delay = reconnectMaxDelay
+		}
+
+		// the pod may have been replaced by a rollout
+		current, err := getPod(ctx, o.mgr.GetClient(), o.namespace, o.componentName)
+		if err == nil {
+			pod = current
+		}
+		o.setState(generation, index, devfile.PortForwardConnecting, nil)
+	}
+}
+
+// setState changes the state of a port of the current forwarding, and reports the state of all the ports
+func (o *portForwarder) setState(generation int64, index int, state devfile.PortForwardState, err error) {
+	o.mu.Lock()
+	if generation != o.generation {
+		o.mu.Unlock()
+		return
+	}
+	o.ports[index].State = state
+	o.ports[index].Error = ""
+	if err != nil {
+		o.ports[index].Error = err.Error()
+	}
+	report := o.snapshotLocked()
+	o.mu.Unlock()
+
+	report()
+}
+
+// snapshotLocked copies the state of the ports, and returns a function reporting this state.
+// The lock must be held when calling snapshotLocked, and must be released before calling the returned function,
+// so the other ports are not blocked while the status is written
+func (o *portForwarder) snapshotLocked() func() {
+	setStatus := o.setStatus
+	if setStatus == nil {
+		return func() {}
+	}
+	ports := make([]devfile.ForwardedPort, len(o.ports))
+	copy(ports, o.ports)
+	o.reportSeq++
+	seq := o.reportSeq
+	return func() {
+		o.reportMu.Lock()
+		defer o.reportMu.Unlock()
+		if seq <= o.reportedSeq {
+			return
+		}
+		o.reportedSeq = seq
+		_ = setStatus(devfile.StatusContent{
+			ForwardedPorts: ports,
+		})
+	}
+}
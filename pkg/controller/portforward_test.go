@@ -0,0 +1,121 @@
+package controller
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/feloy/ododev/pkg/devfile"
+)
+
+func TestPortForwarderAssignedPorts(t *testing.T) {
+	tests := []struct {
+		name     string
+		assigned map[string]int
+		previous map[string]int
+		want     map[string]int
+	}{
+		{
+			name: "no port",
+			want: map[string]int{},
+		},
+		{
+			name:     "ports of the previous sessions kept",
+			assigned: map[string]int{"http": 20001},
+			previous: map[string]int{"admin": 20002},
+			want:     map[string]int{"http": 20001, "admin": 20002},
+		},
+		{
+			name:     "previous ports overridden",
+			assigned: map[string]int{"http": 20001},
+			previous: map[string]int{"http": 20003},
+			want:     map[string]int{"http": 20001},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newPortForwarder(nil, "ns", "component")
+			o.assigned = tt.assigned
+			if got := o.assignedPorts(tt.previous); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("assignedPorts() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPortForwarderSetState(t *testing.T) {
+	tests := []struct {
+		name       string
+		generation int64
+		state      devfile.PortForwardState
+		err        error
+		// want is the reported port, nil if no state is reported
+		want *devfile.ForwardedPort
+	}{
+		{
+			name:       "listening",
+			generation: 1,
+			state:      devfile.PortForwardListening,
+			want:       &devfile.ForwardedPort{EndpointName: "http", State: devfile.PortForwardListening},
+		},
+		{
+			name:       "failed",
+			generation: 1,
+			state:      devfile.PortForwardFailed,
+			err:        errors.New("connection lost"),
+			want:       &devfile.ForwardedPort{EndpointName: "http", State: devfile.PortForwardFailed, Error: "connection lost"},
+		},
+		{
+			name:       "previous forwarding",
+			generation: 0,
+			state:      devfile.PortForwardFailed,
+			err:        errors.New("connection lost"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var reported *devfile.ForwardedPort
+			o := newPortForwarder(nil, "ns", "component")
+			o.generation = 1
+			o.ports = []devfile.ForwardedPort{{EndpointName: "http", State: devfile.PortForwardConnecting, Error: "previous error"}}
+			o.setStatus = func(status devfile.StatusContent) error {
+				reported = &status.ForwardedPorts[0]
+				return nil
+			}
+			o.setState(tt.generation, 0, tt.state, tt.err)
+			if !reflect.DeepEqual(reported, tt.want) {
+				t.Errorf("reported port = %+v, want %+v", reported, tt.want)
+			}
+		})
+	}
+}
+
+func TestPortForwarderReport(t *testing.T) {
+	var reported []devfile.PortForwardState
+	o := newPortForwarder(nil, "ns", "component")
+	o.setStatus = func(status devfile.StatusContent) error {
+		// the lock is not held while the status is written
+		_ = o.isForwarding("")
+		reported = append(reported, status.ForwardedPorts[0].State)
+		return nil
+	}
+	o.ports = []devfile.ForwardedPort{{EndpointName: "http", State: devfile.PortForwardConnecting}}
+
+	o.mu.Lock()
+	connecting := o.snapshotLocked()
+	o.ports[0].State = devfile.PortForwardListening
+	listening := o.snapshotLocked()
+	o.ports[0].State = devfile.PortForwardFailed
+	failed := o.snapshotLocked()
+	o.mu.Unlock()
+
+	// the reports are written out of order, an older state must not override a newer one
+	connecting()
+	failed()
+	listening()
+
+	want := []devfile.PortForwardState{devfile.PortForwardConnecting, devfile.PortForwardFailed}
+	if !reflect.DeepEqual(reported, want) {
+		t.Errorf("reported states = %v, want %v", reported, want)
+	}
+}
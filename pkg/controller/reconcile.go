@@ -1,16 +1,20 @@
 package controller
 
 import (
+	"bytes"
 	"context"
 	"fmt"
-	"os"
 	"strconv"
+	"sync/atomic"
+	"time"
 
 	"github.com/devfile/api/v2/pkg/apis/workspaces/v1alpha2"
 	"github.com/feloy/ododev/pkg/container"
 	"github.com/feloy/ododev/pkg/devfile"
+	"github.com/feloy/ododev/pkg/filesystem"
 	"github.com/feloy/ododev/pkg/libdevfile"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -19,6 +23,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/utils/pointer"
 )
@@ -26,10 +31,32 @@ import (
 type ReconcileConfigmap struct {
 	Client  client.Client
 	Manager manager.Manager
+	// Controller is the controller executing the reconciler, used to watch the objects created from Kubernetes components
+	Controller controller.Controller
 
-	portForwardStopChan chan struct{}
+	// portForwarder forwards the ports of the dev pod
+	portForwarder *portForwarder
+
+	// runGeneration is incremented every time the run command is started or stopped,
+	// so a terminated run command can know if it has been stopped by the controller
+	runGeneration int64
+
+	// watchedKinds are the kinds of the objects created from Kubernetes components already watched
+	watchedKinds map[schema.GroupVersionKind]bool
+
+	// postStartPodUID is the pod in which the postStart commands failed last,
+	// and postStartDelay the delay before executing them again in this pod
+	postStartPodUID types.UID
+	postStartDelay  time.Duration
 }
 
+const (
+	// postStartInitialDelay is the delay before executing again the postStart commands after they failed the first time in a pod
+	postStartInitialDelay = 1 * time.Second
+	// postStartMaxDelay is the maximum delay before executing again the postStart commands
+	postStartMaxDelay = 5 * time.Minute
+)
+
 var _ reconcile.Reconciler = &ReconcileConfigmap{}
 
 func (r *ReconcileConfigmap) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
@@ -46,20 +73,17 @@ func (r *ReconcileConfigmap) Reconcile(ctx context.Context, request reconcile.Re
 		return reconcile.Result{}, err
 	}
 
-	ownerRef := metav1.OwnerReference{
-		APIVersion:         cm.APIVersion,
-		Kind:               cm.Kind,
-		Name:               cm.GetName(),
-		UID:                cm.GetUID(),
-		Controller:         pointer.Bool(true),
-		BlockOwnerDeletion: pointer.Bool(true),
-	}
+	ownerRef := devfile.GetSpecOwnerReference(&cm)
 
-	devfileObj, componentName, completeSyncModTime, err := devfile.InfoFromDevfileConfigMap(ctx, r.Client, cm)
+	spec, err := devfile.InfoFromDevfileConfigMap(ctx, r.Client, cm)
 	if err != nil {
 		log.Error(err, "getting devfile from configmap")
 		return reconcile.Result{}, err
 	}
+	devfileObj, componentName, completeSyncGeneration := spec.Devfile, spec.ComponentName, spec.CompleteSyncGeneration
+	setStatus := func(status devfile.StatusContent) error {
+		return devfile.SetStatus(ctx, r.Client, r.Manager.GetAPIReader(), request.Namespace, componentName, ownerRef, status)
+	}
 
 	// Apply the Kubernetes components
 	k8sComponents, err := devfile.GetKubernetesComponentsToPush(*devfileObj)
@@ -74,12 +98,43 @@ func (r *ReconcileConfigmap) Reconcile(ctx context.Context, request reconcile.Re
 		log.Info("pushing component " + k8sc.Name)
 
 	}
-	err = pushKubernetesComponents(ctx, r.Client, k8sComponents, request.Namespace, ownerRef)
+	inventory, conflicts, err := pushKubernetesComponents(ctx, r.Client, k8sComponents, spec.KubernetesManifests, spec.Variables, request.Namespace, componentName, ownerRef)
 	if err != nil {
 		log.Error(err, "pushing Kubernetes resources")
 		return reconcile.Result{}, err
 	}
 
+	// Delete the resources of Kubernetes components removed from the devfile
+	previousStatus, err := devfile.GetStatus(ctx, r.Client, request.Namespace, componentName)
+	if err != nil && !errors.IsNotFound(err) {
+		return reconcile.Result{}, err
+	}
+	err = r.watchKubernetesComponents(inventory, request.Namespace, componentName)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	err = pruneKubernetesComponents(ctx, r.Client, previousStatus.KubernetesComponents, inventory, request.Namespace, componentName)
+	if err != nil {
+		log.Error(err, "deleting Kubernetes resources")
+		return reconcile.Result{}, err
+	}
+	inventoryStatus := previousStatus.Status
+	if inventoryStatus == "" {
+		inventoryStatus = devfile.StatusWaitDeployment
+	}
+	if len(conflicts) > 0 {
+		log.Info("conflicts applying Kubernetes resources", "conflicts", conflicts)
+	}
+	err = devfile.SetStatus(ctx, r.Client, r.Manager.GetAPIReader(), request.Namespace, componentName, ownerRef, devfile.StatusContent{
+		Status:               inventoryStatus,
+		ObservedGeneration:   spec.Generation,
+		KubernetesComponents: inventory,
+		KubernetesConflicts:  conflicts,
+	})
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
 	// Compute the expected deployment
 	var newDep *appsv1.Deployment
 	newDep, err = buildDeployment(*devfileObj, componentName, request.Namespace)
@@ -128,20 +183,21 @@ func (r *ReconcileConfigmap) Reconcile(ctx context.Context, request reconcile.Re
 	)
 
 	if dep.Status.AvailableReplicas < 1 {
-		err = devfile.SetStatus(ctx, r.Client, request.Namespace, componentName, ownerRef, devfile.StatusContent{
-			Status:                devfile.StatusWaitDeployment,
-			SyncedCompleteModTime: pointer.Int64(0),
+		// Stop port forwarding, if any, before its state is cleared
+		r.portForwarder.stop()
+
+		err = devfile.SetStatus(ctx, r.Client, r.Manager.GetAPIReader(), request.Namespace, componentName, ownerRef, devfile.StatusContent{
+			Status:                      devfile.StatusWaitDeployment,
+			SyncedCompleteGeneration:    pointer.String(""),
+			SyncedIncrementalGeneration: pointer.String(""),
+			ForwardedPorts:              []devfile.ForwardedPort{},
 		})
 		if err != nil {
 			return reconcile.Result{}, err
 		}
 
-		// Stop port forwarding, if any
-		if r.portForwardStopChan != nil {
-			fmt.Println("stopping port forwarding")
-			r.portForwardStopChan <- struct{}{}
-			r.portForwardStopChan = nil
-		}
+		// The run command, if any, terminates with the pod
+		atomic.AddInt64(&r.runGeneration, 1)
 		return reconcile.Result{}, nil
 	}
 
@@ -155,7 +211,7 @@ func (r *ReconcileConfigmap) Reconcile(ctx context.Context, request reconcile.Re
 	}
 	if !allInjected {
 		log.Info("missing bindings")
-		err = devfile.SetStatus(ctx, r.Client, request.Namespace, componentName, ownerRef, devfile.StatusContent{
+		err = devfile.SetStatus(ctx, r.Client, r.Manager.GetAPIReader(), request.Namespace, componentName, ownerRef, devfile.StatusContent{
 			Status: devfile.StatusWaitBindings,
 		})
 		if err != nil {
@@ -181,7 +237,7 @@ func (r *ReconcileConfigmap) Reconcile(ctx context.Context, request reconcile.Re
 	}
 
 	if status.Status == devfile.StatusWaitDeployment || status.Status == devfile.StatusWaitBindings {
-		err = devfile.SetStatus(ctx, r.Client, request.Namespace, componentName, ownerRef, devfile.StatusContent{
+		err = devfile.SetStatus(ctx, r.Client, r.Manager.GetAPIReader(), request.Namespace, componentName, ownerRef, devfile.StatusContent{
 			Status: devfile.StatusPodRunning,
 		})
 		if err != nil {
@@ -195,30 +251,179 @@ func (r *ReconcileConfigmap) Reconcile(ctx context.Context, request reconcile.Re
 	if err != nil {
 		return reconcile.Result{}, err
 	}
-	log.Info("get status", "status", status.Status, "synced modtime", status.SyncedCompleteModTime)
-
-	if completeSyncModTime != nil && (status.SyncedCompleteModTime == nil || *completeSyncModTime > *status.SyncedCompleteModTime) {
-		log.Info("syncing file to pod", "pod", pod.GetName(), "modtime", completeSyncModTime, "status modtime", strconv.FormatInt(pointer.Int64Deref(status.SyncedCompleteModTime, 0), 10))
 
-		err = StopDevfileCommand(ctx, r.Client, r.Manager, pod, "runtime")
+	// postStart commands are executed once per pod, before the sources are synchronized
+	if status.PostStartPodUID != string(pod.GetUID()) {
+		postStartCmds, err := libdevfile.GetEventCommands(*devfileObj, devfileObj.Data.GetEvents().PostStart)
 		if err != nil {
 			return reconcile.Result{}, err
 		}
+		if len(postStartCmds) > 0 {
+			log.Info("executing postStart commands", "pod", pod.GetName())
+			syncTargets, err := libdevfile.GetSyncTargets(*devfileObj)
+			if err != nil {
+				return reconcile.Result{}, err
+			}
+			result, err := execEventCommands(ctx, r.Client, r.Manager, pod, syncTargets, postStartCmds, nil, setStatus)
+			if err != nil {
+				return reconcile.Result{}, err
+			}
+			if result != nil {
+				// the postStart commands are executed again in the same pod, with an exponential backoff
+				if r.postStartPodUID != pod.GetUID() {
+					r.postStartPodUID = pod.GetUID()
+					r.postStartDelay = postStartInitialDelay
+				}
+				delay := r.postStartDelay
+				r.postStartDelay *= 2
+				if r.postStartDelay > postStartMaxDelay {
+					r.postStartDelay = postStartMaxDelay
+				}
+				log.Info("postStart command failed", "exit code", result.ExitCode, "retry in", delay)
+				err = setStatus(devfile.StatusContent{
+					Status:      devfile.StatusPostStartFailed,
+					LastCommand: result,
+				})
+				return reconcile.Result{RequeueAfter: delay}, err
+			}
+		}
+		err = setStatus(devfile.StatusContent{
+			PostStartPodUID: string(pod.GetUID()),
+		})
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+	syncedCompleteGeneration := pointer.StringDeref(status.SyncedCompleteGeneration, "")
+	syncedIncrementalGeneration := pointer.StringDeref(status.SyncedIncrementalGeneration, "")
+	log.Info("get status", "status", status.Status, "synced generation", syncedCompleteGeneration)
+
+	// a complete sync is needed when the complete archive has not been synced to the container yet
+	// the sources, build and run are applied again from scratch when the pod has been replaced or its containers restarted
+	currentPod := getSyncedPod(pod)
+	podChanged := status.SyncedPod == nil || *status.SyncedPod != currentPod
+	completeSyncNeeded := completeSyncGeneration != "" && (podChanged || syncedCompleteGeneration != completeSyncGeneration)
+	// an incremental sync is needed when files have been modified or deleted since the last sync
+	incrementalSyncNeeded := spec.IncrementalSyncGeneration != syncedIncrementalGeneration
+
+	if completeSyncNeeded || incrementalSyncNeeded {
+		log.Info("syncing file to pod", "pod", pod.GetName(), "generation", completeSyncGeneration, "status generation", syncedCompleteGeneration,
+			"incremental generation", spec.IncrementalSyncGeneration, "status incremental generation", syncedIncrementalGeneration)
+
+		// a spec written by a previous version references no archive, the client publishes them
+		// and updates the spec when it starts
+		if (completeSyncNeeded && spec.CompleteArchive == nil) || (spec.IncrementalSyncGeneration != "" && spec.IncrementalArchive == nil) {
+			log.Info("waiting for the archives of the sources to be published")
+			return reconcile.Result{}, nil
+		}
 
-		tarReader, err := os.Open(".odo/complete.tar")
+		// in debug mode, the debug command is executed instead of the run command
+		runKind := v1alpha2.RunCommandGroupKind
+		if spec.Debug {
+			runKind = v1alpha2.DebugCommandGroupKind
+		}
+		runCmd, err := libdevfile.GetDefaultCommand(*devfileObj, runKind)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		runTree, err := libdevfile.ExpandCommand(*devfileObj, runCmd)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		err = checkExecCommands(runTree)
 		if err != nil {
 			return reconcile.Result{}, err
 		}
-		defer tarReader.Close()
 
-		err = container.ExtractTarToContainer(ctx, r.Client, r.Manager, pod, "runtime", "/projects", tarReader) // TODO container name, target path
+		// a hot reload capable run command already running reloads the synchronized sources by itself,
+		// it is not stopped, and the build command is not executed
+		hotReload := !podChanged && canHotReload(runCmd, status.Status)
+
+		if !hotReload {
+			atomic.AddInt64(&r.runGeneration, 1)
+			err = stopCommands(ctx, r.Client, r.Manager, pod, runTree)
+			if err != nil {
+				return reconcile.Result{}, err
+			}
+		}
+
+		syncTargets, err := libdevfile.GetSyncTargets(*devfileObj)
 		if err != nil {
 			return reconcile.Result{}, err
 		}
+		// the incremental archive and the deleted files listed in the spec are cumulative since the complete archive
+		// has been created. When the container already contains a previous generation, only the files changed since
+		// this generation are applied
+		deletedFiles := spec.DeletedFiles
+		var modifiedFiles []string
+		filterIncremental := false
+		if !completeSyncNeeded {
+			if modified, deleted, ok := r.getChangedFiles(ctx, request.Namespace, spec, status); ok {
+				modifiedFiles, deletedFiles, filterIncremental = modified, deleted, true
+			}
+		}
+		var completeArchive, incrementalArchive []byte
+		if completeSyncNeeded {
+			completeArchive, err = r.readArchive(ctx, request.Namespace, spec.CompleteArchive)
+			if err != nil {
+				return reconcile.Result{}, err
+			}
+		}
+		if spec.IncrementalSyncGeneration != "" {
+			incrementalArchive, err = r.readArchive(ctx, request.Namespace, spec.IncrementalArchive)
+			if err != nil {
+				return reconcile.Result{}, err
+			}
+			if filterIncremental {
+				incrementalArchive, err = filterArchive(incrementalArchive, modifiedFiles)
+				if err != nil {
+					log.Info("extracting all the files of the incremental archive", "err", err)
+					incrementalArchive, err = r.readArchive(ctx, request.Namespace, spec.IncrementalArchive)
+					if err != nil {
+						return reconcile.Result{}, err
+					}
+				}
+			}
+		}
+		for _, target := range syncTargets {
+			if completeSyncNeeded {
+				err = r.extractArchive(ctx, pod, target, completeArchive)
+				if err != nil {
+					return reconcile.Result{}, err
+				}
+			}
 
-		err = devfile.SetStatus(ctx, r.Client, request.Namespace, componentName, ownerRef, devfile.StatusContent{
-			Status:                devfile.StatusFilesSynced,
-			SyncedCompleteModTime: completeSyncModTime,
+			// The incremental archive contains the files modified since the complete archive has been created,
+			// or since the generation synced last, it is applied on top of the complete archive
+			if incrementalArchive != nil {
+				err = r.extractArchive(ctx, pod, target, incrementalArchive)
+				if err != nil {
+					return reconcile.Result{}, err
+				}
+			}
+
+			err = container.RemoveFiles(ctx, r.Client, r.Manager, pod, target.ContainerName, target.Path, deletedFiles)
+			if err != nil {
+				return reconcile.Result{}, err
+			}
+		}
+
+		if hotReload {
+			log.Info("files synced to hot reload capable run command", "command", runCmd.Id)
+			err = devfile.SetStatus(ctx, r.Client, r.Manager.GetAPIReader(), request.Namespace, componentName, ownerRef, devfile.StatusContent{
+				Status:                      devfile.StatusRunCommandHotReloaded,
+				SyncedCompleteGeneration:    pointer.String(completeSyncGeneration),
+				SyncedIncrementalGeneration: pointer.String(spec.IncrementalSyncGeneration),
+				SyncedPod:                   &currentPod,
+			})
+			return reconcile.Result{}, err
+		}
+
+		err = devfile.SetStatus(ctx, r.Client, r.Manager.GetAPIReader(), request.Namespace, componentName, ownerRef, devfile.StatusContent{
+			Status:                      devfile.StatusFilesSynced,
+			SyncedCompleteGeneration:    pointer.String(completeSyncGeneration),
+			SyncedIncrementalGeneration: pointer.String(spec.IncrementalSyncGeneration),
+			SyncedPod:                   &currentPod,
 		})
 		if err != nil {
 			return reconcile.Result{}, err
@@ -229,53 +434,162 @@ func (r *ReconcileConfigmap) Reconcile(ctx context.Context, request reconcile.Re
 		if err != nil {
 			return reconcile.Result{}, err
 		}
-		err = ExecDevfileCommand(ctx, r.Client, r.Manager, pod, "runtime", "/projects", buildCmd)
+		buildTree, err := libdevfile.ExpandCommand(*devfileObj, buildCmd)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		err = checkExecCommands(buildTree)
 		if err != nil {
 			return reconcile.Result{}, err
 		}
+		buildOutput := newTailWriter(maxOutputSize)
+		startedAt := metav1.Now()
+		err = newCommandRunner(r.Client, r.Manager, pod, syncTargets, setStatus).run(ctx, buildTree, buildOutput)
+		buildResult := newCommandResult(buildCmd, startedAt, buildOutput, err)
+		if err != nil {
+			if buildResult.ExitCode < 0 {
+				// the command has not been executed
+				return reconcile.Result{}, err
+			}
+			log.Info("build command failed", "exit code", buildResult.ExitCode)
+			// wait for the next change of the sources
+			err = devfile.SetStatus(ctx, r.Client, r.Manager.GetAPIReader(), request.Namespace, componentName, ownerRef, devfile.StatusContent{
+				Status:      devfile.StatusBuildFailed,
+				LastCommand: buildResult,
+			})
+			return reconcile.Result{}, err
+		}
 
-		err = devfile.SetStatus(ctx, r.Client, request.Namespace, componentName, ownerRef, devfile.StatusContent{
-			Status:                devfile.StatusBuildCommandExecuted,
-			SyncedCompleteModTime: completeSyncModTime,
+		err = devfile.SetStatus(ctx, r.Client, r.Manager.GetAPIReader(), request.Namespace, componentName, ownerRef, devfile.StatusContent{
+			Status:      devfile.StatusBuildCommandExecuted,
+			LastCommand: buildResult,
 		})
 		if err != nil {
 			return reconcile.Result{}, err
 		}
 
-		// restart port forwarding
-		if r.portForwardStopChan == nil {
-			portPairs, err := libdevfile.GetPortPairs(*devfileObj)
-			if err != nil {
-				return reconcile.Result{}, err
+		// forward the ports, to the new pod after a rollout
+		if !r.portForwarder.isForwarding(pod.GetUID()) {
+			r.portForwarder.stop()
+			// the devfile can have been modified since the ports have been pinned by the user
+			if err := libdevfile.CheckPinnedPorts(*devfileObj, spec.PinnedPorts, spec.Debug); err != nil {
+				log.Info("ignoring pinned ports", "err", err)
 			}
-			fmt.Printf("starting port forwarding in ports: %s\n", portPairs)
-			r.portForwardStopChan, err = container.SetupPortForwarding(r.Manager, r.Client, pod, portPairs, os.Stdout, os.Stderr)
+			pairs, err := libdevfile.GetPortPairs(*devfileObj, libdevfile.LocalPorts{
+				Pinned:   spec.PinnedPorts,
+				Previous: r.portForwarder.assignedPorts(spec.PreviousPorts),
+			}, spec.Debug)
 			if err != nil {
 				return reconcile.Result{}, err
 			}
+			r.portForwarder.start(ctx, pod, pairs, setStatus)
 		}
 
-		// run command
-		runCmd, err := libdevfile.GetDefaultCommand(*devfileObj, v1alpha2.RunCommandGroupKind)
-		if err != nil {
-			return reconcile.Result{}, err
-		}
+		// run command, restarted depending on the restart policy
+		runGeneration := atomic.AddInt64(&r.runGeneration, 1)
+		go r.superviseRunCommand(ctx, pod, syncTargets, runTree, spec.RestartPolicy, runGeneration, setStatus)
+	}
 
-		go func() {
+	return reconcile.Result{}, nil
+}
 
-			_ = devfile.SetStatus(ctx, r.Client, request.Namespace, componentName, ownerRef, devfile.StatusContent{
-				Status:                devfile.StatusRunCommandRunning,
-				SyncedCompleteModTime: completeSyncModTime,
-			})
+// getSyncedPod returns the identity of the pod and of its containers' restarts, to which the sources are synced
+func getSyncedPod(pod *corev1.Pod) devfile.SyncedPod {
+	result := devfile.SyncedPod{
+		UID: string(pod.GetUID()),
+	}
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		result.RestartCount += containerStatus.RestartCount
+	}
+	return result
+}
 
-			err = ExecDevfileCommand(ctx, r.Client, r.Manager, pod, "runtime", "/projects", runCmd)
-			if err != nil {
-				log.Info("terminate run command with err", "err", err)
-			} else {
-				log.Info("terminate run command normally")
-			}
-		}()
+// getChangedFiles returns the files modified and deleted since the generation synced last, computed from
+// the manifests of the synced and current sources. ok is false when the manifests are not available
+func (r *ReconcileConfigmap) getChangedFiles(ctx context.Context, namespace string, spec *devfile.SpecContent, status devfile.StatusContent) (modified []string, deleted []string, ok bool) {
+	log := log.FromContext(ctx)
+
+	syncedGeneration := pointer.StringDeref(status.SyncedIncrementalGeneration, "")
+	if syncedGeneration == "" {
+		syncedGeneration = pointer.StringDeref(status.SyncedCompleteGeneration, "")
+	}
+	if syncedGeneration == "" || spec.IncrementalSyncGeneration == "" {
+		return nil, nil, false
+	}
+	synced, err := r.readManifest(ctx, namespace, spec, syncedGeneration)
+	if err != nil || synced == nil {
+		return nil, nil, false
+	}
+	current, err := r.readManifest(ctx, namespace, spec, spec.IncrementalSyncGeneration)
+	if err != nil || current == nil {
+		return nil, nil, false
 	}
+	modified, deleted = filesystem.Diff(synced, current)
+	log.Info("files changed since last sync", "modified", modified, "deleted", deleted)
+	return modified, deleted, true
+}
 
-	return reconcile.Result{}, nil
+// filterArchive returns an archive containing only the modified files of archive, or nil if no file is modified
+func filterArchive(archive []byte, modified []string) ([]byte, error) {
+	if len(modified) == 0 {
+		return nil, nil
+	}
+	return filesystem.FilterTar(archive, modified)
+}
+
+// readManifest returns the manifest of the generation published by the client, or nil if not referenced by the spec
+func (r *ReconcileConfigmap) readManifest(ctx context.Context, namespace string, spec *devfile.SpecContent, generation string) (filesystem.Manifest, error) {
+	ref, ok := spec.Manifests[generation]
+	if !ok {
+		return nil, nil
+	}
+	data, err := devfile.ReadBlob(ctx, r.Client, namespace, ref)
+	if err != nil {
+		return nil, err
+	}
+	return filesystem.DecodeManifest(data)
+}
+
+// readArchive returns the content of an archive of the sources published by the client
+func (r *ReconcileConfigmap) readArchive(ctx context.Context, namespace string, ref *devfile.BlobRef) ([]byte, error) {
+	if ref == nil {
+		return nil, fmt.Errorf("archive of the sources not published")
+	}
+	return devfile.ReadBlob(ctx, r.Client, namespace, *ref)
+}
+
+// extractArchive extracts an archive of the sources into the sync target
+func (r *ReconcileConfigmap) extractArchive(ctx context.Context, pod *corev1.Pod, target libdevfile.SyncTarget, archive []byte) error {
+	return container.ExtractTarToContainer(ctx, r.Client, r.Manager, pod, target.ContainerName, target.Path, bytes.NewReader(archive))
+}
+
+// canHotReload returns true if the run command is hot reload capable and already running
+func canHotReload(runCmd v1alpha2.Command, status devfile.Status) bool {
+	return runCmd.Exec != nil && runCmd.Exec.GetHotReloadCapable() &&
+		(status == devfile.StatusRunCommandRunning || status == devfile.StatusRunCommandHotReloaded)
+}
+
+// getWorkingDir returns the directory in which the sources are synchronized for the container of the command,
+// used when the command does not define a working directory
+func getWorkingDir(syncTargets []libdevfile.SyncTarget, cmd v1alpha2.Command) string {
+	if cmd.Exec == nil {
+		return ""
+	}
+	for _, target := range syncTargets {
+		if target.ContainerName == cmd.Exec.Component {
+			return target.Path
+		}
+	}
+	return ""
+}
+
+// newCommandResult returns the result of a command executed with ExecDevfileCommand
+func newCommandResult(cmd v1alpha2.Command, startedAt metav1.Time, output *tailWriter, err error) *devfile.CommandResult {
+	return &devfile.CommandResult{
+		Command:    cmd.Id,
+		ExitCode:   getExitCode(err),
+		Output:     output.String(),
+		StartedAt:  startedAt,
+		FinishedAt: metav1.Now(),
+	}
 }
@@ -15,6 +15,7 @@ import (
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/types"
@@ -104,7 +105,7 @@ var _ = Describe("Static controller", func() {
 						expectedOwnerReference = metav1.OwnerReference{
 							Kind:               "ConfigMap",
 							APIVersion:         "v1",
-							Name:               "devfile-spec",
+							Name:               devfile.GetSpecConfigMapName(componentName),
 							UID:                created.UID,
 							Controller:         pointer.Bool(true),
 							BlockOwnerDeletion: pointer.Bool(true),
@@ -163,11 +164,16 @@ var _ = Describe("Static controller", func() {
 							BeforeEach(func() {
 								absPath, err := filepath.Abs("tests/project")
 								Expect(err).To(Succeed())
-								modTime, err := filesystem.Archive(absPath, ".odo/complete.tar", nil)
+								manifest, err := filesystem.Archive(absPath, ".odo/complete.tar", nil, ".odo")
+								Expect(err).To(Succeed())
+								data, err := os.ReadFile(".odo/complete.tar")
+								Expect(err).To(Succeed())
+								archive, err := devfile.PublishBlob(ctx, k8sClient, namespace, componentName, "complete", data)
 								Expect(err).To(Succeed())
 								_, err = devfile.CreateConfigMapFromDevfile(ctx, k8sClient, namespace, componentName, devfile.ConfigMapContent{
-									Devfile:             test.devfile,
-									CompleteSyncModTime: modTime,
+									Devfile:                test.devfile,
+									CompleteSyncGeneration: manifest.Digest(),
+									CompleteArchive:        archive,
 								})
 								Expect(err).To(Succeed())
 							})
@@ -256,6 +262,51 @@ var _ = Describe("Static controller", func() {
 					})
 				})
 			}
+
+			When("a Devfile configmap with a service binding is created", func() {
+
+				var created *corev1.ConfigMap
+
+				bindingKey := types.NamespacedName{
+					Name:      "my-go-app-cluster-sample",
+					Namespace: namespace,
+				}
+
+				getBinding := func() error {
+					var binding unstructured.Unstructured
+					binding.SetAPIVersion("binding.operators.coreos.com/v1alpha1")
+					binding.SetKind("ServiceBinding")
+					return k8sClient.Get(ctx, bindingKey, &binding)
+				}
+
+				BeforeEach(func() {
+					var err error
+					created, err = devfile.CreateConfigMapFromDevfile(ctx, k8sClient, namespace, componentName, devfile.ConfigMapContent{
+						Devfile: "tests/devfile-binding.yaml",
+					})
+					Expect(err).Should(Succeed())
+				})
+
+				AfterEach(func() {
+					Expect(k8sClient.Delete(ctx, created)).Should(Succeed())
+				})
+
+				When("the service binding is removed from the Devfile", func() {
+					BeforeEach(func() {
+						Eventually(getBinding, timeout, interval).Should(Succeed())
+						_, err := devfile.CreateConfigMapFromDevfile(ctx, k8sClient, namespace, componentName, devfile.ConfigMapContent{
+							Devfile: "tests/devfile.yaml",
+						})
+						Expect(err).Should(Succeed())
+					})
+
+					Specify("the service binding is deleted", func() {
+						Eventually(func() bool {
+							return errors.IsNotFound(getBinding())
+						}, timeout, interval).Should(BeTrue())
+					})
+				})
+			})
 		})
 	})
 })
@@ -0,0 +1,123 @@
+package controller
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/feloy/ododev/pkg/devfile"
+	"github.com/feloy/ododev/pkg/libdevfile"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// restartInitialDelay is the delay before the first restart of the run command
+	restartInitialDelay = 1 * time.Second
+	// restartMaxDelay is the maximum delay before restarting the run command
+	restartMaxDelay = 5 * time.Minute
+	// restartResetDuration is the duration after which a run command is considered as started correctly,
+	// the delay before the next restart is reset to restartInitialDelay
+	restartResetDuration = 1 * time.Minute
+)
+
+// superviseRunCommand executes the run command, and restarts it with an exponential backoff when it exits,
+// depending on the restart policy. It returns when the command exits and must not be restarted,
+// or when the command has been stopped by the controller (the run generation changed)
+func (r *ReconcileConfigmap) superviseRunCommand(
+	ctx context.Context,
+	pod *corev1.Pod,
+	syncTargets []libdevfile.SyncTarget,
+	runTree libdevfile.CommandTree,
+	policy devfile.RestartPolicy,
+	runGeneration int64,
+	setStatus func(status devfile.StatusContent) error,
+) {
+	log := log.FromContext(ctx)
+
+	stopped := func() bool {
+		return atomic.LoadInt64(&r.runGeneration) != runGeneration
+	}
+
+	runner := newCommandRunner(r.Client, r.Manager, pod, syncTargets, setStatus)
+	runCmd := runTree.Command
+
+	delay := restartInitialDelay
+	for restartCount := 0; ; restartCount++ {
+		count := restartCount
+		_ = setStatus(devfile.StatusContent{
+			Status:       devfile.StatusRunCommandRunning,
+			RestartCount: &count,
+		})
+
+		output := newTailWriter(maxOutputSize)
+		startedAt := metav1.Now()
+		err := runner.run(ctx, runTree, output)
+		if err != nil {
+			log.Info("terminate run command with err", "err", err)
+		} else {
+			log.Info("terminate run command normally")
+		}
+
+		if stopped() {
+			return
+		}
+		result := newCommandResult(runCmd, startedAt, output, err)
+
+		if !shouldRestart(policy, result.ExitCode) {
+			_ = setStatus(devfile.StatusContent{
+				Status:      devfile.StatusRunCommandExited,
+				LastCommand: result,
+			})
+			return
+		}
+
+		// The processes of the command can still be running, as when the stream of the exec has been interrupted,
+		// they must be stopped before the command is restarted
+		err = stopCommands(ctx, r.Client, r.Manager, pod, runTree)
+		if err != nil {
+			log.Error(err, "unable to stop the run command, it will not be restarted")
+			_ = setStatus(devfile.StatusContent{
+				Status:      devfile.StatusRunCommandExited,
+				LastCommand: result,
+			})
+			return
+		}
+
+		if result.FinishedAt.Sub(result.StartedAt.Time) > restartResetDuration {
+			delay = restartInitialDelay
+		}
+		log.Info("restarting run command", "exit code", result.ExitCode, "delay", delay)
+		_ = setStatus(devfile.StatusContent{
+			Status:      devfile.StatusRunCommandBackOff,
+			LastCommand: result,
+		})
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		if stopped() {
+			return
+		}
+
+		delay *= 2
+		if delay > restartMaxDelay {
+			delay = restartMaxDelay
+		}
+	}
+}
+
+// shouldRestart returns true if a run command having exited with exitCode must be restarted
+func shouldRestart(policy devfile.RestartPolicy, exitCode int) bool {
+	switch policy {
+	case devfile.RestartPolicyAlways:
+		return true
+	case devfile.RestartPolicyOnFailure:
+		return exitCode != 0
+	default:
+		return false
+	}
+}
@@ -0,0 +1,130 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/devfile/api/v2/pkg/apis/workspaces/v1alpha2"
+	"github.com/feloy/ododev/pkg/devfile"
+	"k8s.io/utils/pointer"
+)
+
+func TestShouldRestart(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   devfile.RestartPolicy
+		exitCode int
+		want     bool
+	}{
+		{
+			name:     "always, exited normally",
+			policy:   devfile.RestartPolicyAlways,
+			exitCode: 0,
+			want:     true,
+		},
+		{
+			name:     "always, failed",
+			policy:   devfile.RestartPolicyAlways,
+			exitCode: 1,
+			want:     true,
+		},
+		{
+			name:     "on failure, exited normally",
+			policy:   devfile.RestartPolicyOnFailure,
+			exitCode: 0,
+			want:     false,
+		},
+		{
+			name:     "on failure, failed",
+			policy:   devfile.RestartPolicyOnFailure,
+			exitCode: 137,
+			want:     true,
+		},
+		{
+			name:     "on failure, stream error",
+			policy:   devfile.RestartPolicyOnFailure,
+			exitCode: -1,
+			want:     true,
+		},
+		{
+			name:     "never, failed",
+			policy:   devfile.RestartPolicyNever,
+			exitCode: 1,
+			want:     false,
+		},
+		{
+			name:     "unknown policy",
+			policy:   devfile.RestartPolicy("Sometimes"),
+			exitCode: 1,
+			want:     false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := shouldRestart(tt.policy, tt.exitCode); got != tt.want {
+				t.Errorf("shouldRestart() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanHotReload(t *testing.T) {
+	hotReloadCommand := execCommand("run", "runtime")
+	hotReloadCommand.Exec.HotReloadCapable = pointer.Bool(true)
+	compositeCommand := v1alpha2.Command{
+		Id: "run",
+		CommandUnion: v1alpha2.CommandUnion{
+			Composite: &v1alpha2.CompositeCommand{Commands: []string{"start"}},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		runCmd v1alpha2.Command
+		status devfile.Status
+		want   bool
+	}{
+		{
+			name:   "hot reload capable command running",
+			runCmd: hotReloadCommand,
+			status: devfile.StatusRunCommandRunning,
+			want:   true,
+		},
+		{
+			name:   "hot reload capable command already hot reloaded",
+			runCmd: hotReloadCommand,
+			status: devfile.StatusRunCommandHotReloaded,
+			want:   true,
+		},
+		{
+			name:   "hot reload capable command exited",
+			runCmd: hotReloadCommand,
+			status: devfile.StatusRunCommandExited,
+			want:   false,
+		},
+		{
+			name:   "hot reload capable command not started yet",
+			runCmd: hotReloadCommand,
+			status: devfile.StatusPodRunning,
+			want:   false,
+		},
+		{
+			name:   "command not hot reload capable",
+			runCmd: execCommand("run", "runtime"),
+			status: devfile.StatusRunCommandRunning,
+			want:   false,
+		},
+		{
+			name:   "composite command",
+			runCmd: compositeCommand,
+			status: devfile.StatusRunCommandRunning,
+			want:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := canHotReload(tt.runCmd, tt.status); got != tt.want {
+				t.Errorf("canHotReload() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
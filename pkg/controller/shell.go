@@ -0,0 +1,91 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/devfile/library/pkg/devfile/parser"
+	"k8s.io/client-go/tools/remotecommand"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/feloy/ododev/pkg/container"
+	"github.com/feloy/ododev/pkg/libdevfile"
+)
+
+// ExecOptions defines a command executed by ExecInContainer
+type ExecOptions struct {
+	// Container is the container in which the command is executed,
+	// the first container in which the sources are synchronized if empty
+	Container string
+	// Command is the command to execute, an interactive shell if empty
+	Command []string
+	Stdin   io.Reader
+	Stdout  io.Writer
+	// Stderr is not used when the command is attached to a terminal
+	Stderr io.Writer
+	// TTY is true to attach the command to a terminal
+	TTY bool
+	// SizeQueue returns the sizes of the local terminal, when the command is attached to a terminal
+	SizeQueue remotecommand.TerminalSizeQueue
+}
+
+// ExecInContainer executes a command in a container of the pod of the component,
+// from the directory in which the sources are synchronized. The manager does not need to be started.
+// If the command exits with a non-zero code, the error returned implements the ExitError interface
+// from "k8s.io/client-go/util/exec"
+func ExecInContainer(ctx context.Context, mgr manager.Manager, namespace string, componentName string, devfileObj parser.DevfileObj, options ExecOptions) error {
+	pod, err := getPod(ctx, mgr.GetAPIReader(), namespace, componentName)
+	if err != nil {
+		return err
+	}
+	syncTargets, err := libdevfile.GetSyncTargets(devfileObj)
+	if err != nil {
+		return err
+	}
+
+	containerName := options.Container
+	if containerName == "" {
+		if len(syncTargets) == 0 {
+			return fmt.Errorf("no container in which the sources are synchronized, a container must be specified")
+		}
+		containerName = syncTargets[0].ContainerName
+	}
+	found := false
+	for _, c := range pod.Spec.Containers {
+		if c.Name == containerName {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("container %q not found in pod %q", containerName, pod.GetName())
+	}
+
+	var workingDir string
+	for _, target := range syncTargets {
+		if target.ContainerName == containerName {
+			workingDir = target.Path
+			break
+		}
+	}
+
+	args := getExecArgs(workingDir, options.Command)
+	if options.TTY {
+		return container.ExecTTY(ctx, mgr, pod, containerName, args, options.Stdin, options.Stdout, options.SizeQueue)
+	}
+	return container.Exec(ctx, mgr.GetClient(), mgr, pod, containerName, args, options.Stdout, options.Stderr, options.Stdin, false)
+}
+
+// getExecArgs returns the arguments executing the command from the working directory.
+// When no command is given, a shell is started, bash if available
+func getExecArgs(workingDir string, command []string) []string {
+	script := `exec "$@"`
+	if len(command) == 0 {
+		script = `if command -v bash > /dev/null; then exec bash; else exec sh; fi`
+	}
+	if workingDir != "" {
+		script = fmt.Sprintf("cd %s && %s", shellQuote(workingDir), script)
+	}
+	return append([]string{"/bin/sh", "-c", script, "sh"}, command...)
+}
@@ -0,0 +1,60 @@
+package controller
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestGetExecArgs(t *testing.T) {
+	tests := []struct {
+		name       string
+		workingDir string
+		command    []string
+		want       []string
+	}{
+		{
+			name:    "command",
+			command: []string{"ls", "-l"},
+			want:    []string{"/bin/sh", "-c", `exec "$@"`, "sh", "ls", "-l"},
+		},
+		{
+			name:       "command from the working directory",
+			workingDir: "/projects",
+			command:    []string{"ls", "-l"},
+			want:       []string{"/bin/sh", "-c", `cd '/projects' && exec "$@"`, "sh", "ls", "-l"},
+		},
+		{
+			name:       "shell",
+			workingDir: "/projects",
+			want:       []string{"/bin/sh", "-c", `cd '/projects' && if command -v bash > /dev/null; then exec bash; else exec sh; fi`, "sh"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getExecArgs(tt.workingDir, tt.command); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getExecArgs() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetExecArgsShell(t *testing.T) {
+	if _, err := os.Stat("/bin/sh"); err != nil {
+		t.Skip("/bin/sh not available")
+	}
+	workingDir := filepath.Join(t.TempDir(), "it's a dir")
+	if err := os.Mkdir(workingDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	args := getExecArgs(workingDir, []string{"sh", "-c", `printf '%s|%s' "$(pwd)" "$1"`, "sh", "$HOME 'quoted'"})
+	out, err := exec.Command(args[0], args[1:]...).Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := workingDir + "|$HOME 'quoted'"; string(out) != want {
+		t.Errorf("output = %q, want %q", out, want)
+	}
+}
@@ -5,56 +5,58 @@ import (
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
 
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"github.com/feloy/ododev/pkg/devfile"
+	bindingApi "github.com/redhat-developer/service-binding-operator/apis/binding/v1alpha1"
 )
 
+// StartManager starts the controller reconciling the spec of the component.
+// The sources are read from the cluster, where they are published by the client
 func StartManager(ctx context.Context, mgr manager.Manager, namespace string, componentName string) error {
 
+	// index the phase of the pods, to list the running pods from the cache
+	if err := mgr.GetFieldIndexer().IndexField(ctx, &corev1.Pod{}, podPhaseField, indexPodPhase); err != nil {
+		return err
+	}
+
+	r := &ReconcileConfigmap{
+		Client:        mgr.GetClient(),
+		Manager:       mgr,
+		portForwarder: newPortForwarder(mgr, namespace, componentName),
+	}
 	c, err := controller.New("devfile-controller", mgr, controller.Options{
-		Reconciler: &ReconcileConfigmap{
-			Client:  mgr.GetClient(),
-			Manager: mgr,
-		},
+		Reconciler: r,
 	})
 	if err != nil {
 		return err
 	}
+	r.Controller = c
 
 	configMapPredicate := predicate.Funcs{
 		UpdateFunc: func(e event.UpdateEvent) bool {
-			// The object doesn't contain label "devfile-spec=<component-name>", so the event will be
-			// ignored.
-			if cmp, ok := e.ObjectNew.GetLabels()[devfile.DevfileSpecLabel]; !ok || cmp != componentName {
+			if !isComponentSpec(e.ObjectNew, componentName) {
 				return false
 			}
-
 			return e.ObjectOld != e.ObjectNew
 		},
 		CreateFunc: func(e event.CreateEvent) bool {
-			if cmp, ok := e.Object.GetLabels()[devfile.DevfileSpecLabel]; !ok || cmp != componentName {
-				return false
-			}
-			return true
+			return isComponentSpec(e.Object, componentName)
 		},
 		DeleteFunc: func(e event.DeleteEvent) bool {
-			if cmp, ok := e.Object.GetLabels()[devfile.DevfileSpecLabel]; !ok || cmp != componentName {
-				return false
-			}
-			return true
+			return isComponentSpec(e.Object, componentName)
 		},
 		GenericFunc: func(e event.GenericEvent) bool {
-			if cmp, ok := e.Object.GetLabels()[devfile.DevfileSpecLabel]; !ok || cmp != componentName {
-				return false
-			}
-			return true
+			return isComponentSpec(e.Object, componentName)
 		},
 	}
 
@@ -62,14 +64,78 @@ func StartManager(ctx context.Context, mgr manager.Manager, namespace string, co
 		return err
 	}
 
-	// Watch Deployments and enqueue owning ConfigMap key
+	// Watch Deployments of the component and enqueue owning ConfigMap key
+	deploymentPredicate := predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return obj.GetName() == getDeploymentName(componentName)
+	})
 	if err := c.Watch(&source.Kind{Type: &appsv1.Deployment{}},
-		&handler.EnqueueRequestForOwner{OwnerType: &corev1.ConfigMap{}, IsController: true}); err != nil {
+		&handler.EnqueueRequestForOwner{OwnerType: &corev1.ConfigMap{}, IsController: true}, deploymentPredicate); err != nil {
+		return err
+	}
+
+	// Watch Pods of the component
+	podPredicate := predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return obj.GetLabels()["component"] == componentName
+	})
+	if err := c.Watch(&source.Kind{Type: &corev1.Pod{}}, enqueueComponentSpec(namespace, componentName), podPredicate); err != nil {
 		return err
 	}
 
+	// Watch ServiceBindings targeting the Deployment of the component, if the ServiceBinding kind is known
+	if isServiceBindingKindKnown(mgr) {
+		bindingPredicate := predicate.NewPredicateFuncs(func(obj client.Object) bool {
+			binding, ok := obj.(*bindingApi.ServiceBinding)
+			return ok && isBindingForDeployment(*binding, getDeploymentName(componentName))
+		})
+		if err := c.Watch(&source.Kind{Type: &bindingApi.ServiceBinding{}}, enqueueComponentSpec(namespace, componentName), bindingPredicate); err != nil {
+			return err
+		}
+	}
+
+	// The objects created from Kubernetes components are watched by the reconciler,
+	// when they are added to the inventory
+
 	if err := mgr.Start(ctx); err != nil {
 		return err
 	}
 	return nil
 }
+
+// isComponentSpec returns true if the object is the configmap containing the spec of the component
+func isComponentSpec(obj client.Object, componentName string) bool {
+	// The object is not named "<component-name>-devfile-spec", so the event will be ignored
+	if obj.GetName() != devfile.GetSpecConfigMapName(componentName) {
+		return false
+	}
+	// The object doesn't contain label "devfile-spec=<component-name>", so the event will be
+	// ignored.
+	if cmp, ok := obj.GetLabels()[devfile.DevfileSpecLabel]; !ok || cmp != componentName {
+		return false
+	}
+	return true
+}
+
+// enqueueComponentSpec returns an event handler enqueuing the key of the spec configmap of the component
+func enqueueComponentSpec(namespace string, componentName string) handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(func(client.Object) []reconcile.Request {
+		return []reconcile.Request{
+			{
+				NamespacedName: types.NamespacedName{
+					Namespace: namespace,
+					Name:      devfile.GetSpecConfigMapName(componentName),
+				},
+			},
+		}
+	})
+}
+
+// isServiceBindingKindKnown returns true if the ServiceBinding kind is registered in the scheme
+// and its resource is served by the cluster
+func isServiceBindingKindKnown(mgr manager.Manager) bool {
+	gvk := bindingApi.GroupVersion.WithKind("ServiceBinding")
+	if !mgr.GetScheme().Recognizes(gvk) {
+		return false
+	}
+	_, err := mgr.GetRESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version)
+	return err == nil
+}
@@ -0,0 +1,61 @@
+package controller
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIsComponentSpec(t *testing.T) {
+	tests := []struct {
+		name    string
+		objName string
+		labels  map[string]string
+		want    bool
+	}{
+		{
+			name:    "spec of the component",
+			objName: "my-component-devfile-spec",
+			labels:  map[string]string{"devfile-spec": "my-component"},
+			want:    true,
+		},
+		{
+			name:    "spec of another component",
+			objName: "other-devfile-spec",
+			labels:  map[string]string{"devfile-spec": "other"},
+		},
+		{
+			name:    "spec without label",
+			objName: "my-component-devfile-spec",
+		},
+		{
+			name:    "spec labeled for another component",
+			objName: "my-component-devfile-spec",
+			labels:  map[string]string{"devfile-spec": "other"},
+		},
+		{
+			name:    "status of the component",
+			objName: "my-component-devfile-status",
+			labels:  map[string]string{"devfile-status": "my-component"},
+		},
+		{
+			name:    "spec of a component with a name prefixed by the component name",
+			objName: "my-component-2-devfile-spec",
+			labels:  map[string]string{"devfile-spec": "my-component-2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cm := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   tt.objName,
+					Labels: tt.labels,
+				},
+			}
+			if got := isComponentSpec(cm, "my-component"); got != tt.want {
+				t.Errorf("isComponentSpec() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package controller
+
+import (
+	"context"
+	"io"
+
+	"github.com/devfile/api/v2/pkg/apis/workspaces/v1alpha2"
+	"github.com/devfile/library/pkg/devfile/parser"
+	"github.com/feloy/ododev/pkg/devfile"
+	"github.com/feloy/ododev/pkg/libdevfile"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// ExecTestCommand executes the default test command of the devfile in the pod of the component,
+// and sends its output to output. The manager does not need to be started.
+// The result is returned when the command has been executed, even if it failed
+func ExecTestCommand(ctx context.Context, mgr manager.Manager, namespace string, componentName string, devfileObj parser.DevfileObj, output io.Writer) (*devfile.CommandResult, error) {
+	testCmd, err := libdevfile.GetDefaultCommand(devfileObj, v1alpha2.TestCommandGroupKind)
+	if err != nil {
+		return nil, err
+	}
+	testTree, err := libdevfile.ExpandCommand(devfileObj, testCmd)
+	if err != nil {
+		return nil, err
+	}
+	err = checkExecCommands(testTree)
+	if err != nil {
+		return nil, err
+	}
+
+	pod, err := getPod(ctx, mgr.GetAPIReader(), namespace, componentName)
+	if err != nil {
+		return nil, err
+	}
+	syncTargets, err := libdevfile.GetSyncTargets(devfileObj)
+	if err != nil {
+		return nil, err
+	}
+
+	tail := newTailWriter(maxOutputSize)
+	var w io.Writer = tail
+	if output != nil {
+		w = io.MultiWriter(tail, output)
+	}
+	startedAt := metav1.Now()
+	err = newCommandRunner(mgr.GetClient(), mgr, pod, syncTargets, nil).run(ctx, testTree, w)
+	result := newCommandResult(testCmd, startedAt, tail, err)
+	if result.ExitCode < 0 {
+		// the command has not been executed
+		return nil, err
+	}
+	return result, nil
+}
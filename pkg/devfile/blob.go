@@ -0,0 +1,160 @@
+package devfile
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/devfile/library/pkg/devfile/generator"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// BlobLabel is the label set to the configmaps containing the chunks of the blobs of a component
+	BlobLabel = "devfile-blob"
+	// blobNameLabel is the label containing the name of the blob a chunk is part of
+	blobNameLabel = "devfile-blob-name"
+	// blobDataKey is the key of the binary data containing a chunk
+	blobDataKey = "data"
+	// blobChunkSize is the maximum size of a chunk, below the 1MiB limit of a configmap
+	blobChunkSize = 900 * 1024
+)
+
+// BlobRef references data published into the cluster, split into chunks stored in configmaps
+type BlobRef struct {
+	Name string `json:"name"`
+	// SHA256 is the hex encoded SHA-256 of the complete data
+	SHA256 string     `json:"sha256"`
+	Size   int        `json:"size"`
+	Chunks []ChunkRef `json:"chunks"`
+}
+
+// ChunkRef references a configmap containing a chunk of a blob
+type ChunkRef struct {
+	ConfigMap string `json:"configMap"`
+	// SHA256 is the hex encoded SHA-256 of the chunk
+	SHA256 string `json:"sha256"`
+}
+
+// PublishBlob stores data into configmaps, so the data is accessible from the cluster, and returns the reference to the data.
+// The chunks of a previous version of the blob not used anymore are deleted
+func PublishBlob(ctx context.Context, cli client.Client, namespace string, componentName string, name string, data []byte) (*BlobRef, error) {
+	ref := BlobRef{
+		Name:   name,
+		SHA256: checksum(data),
+		Size:   len(data),
+	}
+	apiVersion, kind := corev1.SchemeGroupVersion.WithKind("ConfigMap").ToAPIVersionAndKind()
+	for i := 0; i == 0 || i*blobChunkSize < len(data); i++ {
+		end := (i + 1) * blobChunkSize
+		if end > len(data) {
+			end = len(data)
+		}
+		chunk := data[i*blobChunkSize : end]
+		configMap := corev1.ConfigMap{
+			BinaryData: map[string][]byte{
+				blobDataKey: chunk,
+			},
+		}
+		configMap.TypeMeta = generator.GetTypeMeta(kind, apiVersion)
+		configMap.SetName(fmt.Sprintf("%s-blob-%s-%d", componentName, name, i))
+		configMap.SetNamespace(namespace)
+		configMap.SetLabels(map[string]string{
+			BlobLabel:     componentName,
+			blobNameLabel: name,
+		})
+		err := cli.Patch(ctx, &configMap, client.Apply, client.FieldOwner("ododev"), client.ForceOwnership)
+		if err != nil {
+			return nil, err
+		}
+		ref.Chunks = append(ref.Chunks, ChunkRef{
+			ConfigMap: configMap.GetName(),
+			SHA256:    checksum(chunk),
+		})
+	}
+
+	// delete the chunks of a previous bigger version of the blob
+	var list corev1.ConfigMapList
+	err := cli.List(ctx, &list, client.InNamespace(namespace), client.MatchingLabels{
+		BlobLabel:     componentName,
+		blobNameLabel: name,
+	})
+	if err != nil {
+		return nil, err
+	}
+	used := make(map[string]bool, len(ref.Chunks))
+	for _, chunk := range ref.Chunks {
+		used[chunk.ConfigMap] = true
+	}
+	for i := range list.Items {
+		if used[list.Items[i].GetName()] {
+			continue
+		}
+		err = cli.Delete(ctx, &list.Items[i])
+		if err != nil && !errors.IsNotFound(err) {
+			return nil, err
+		}
+	}
+	return &ref, nil
+}
+
+// ReadBlob returns the data published with PublishBlob.
+// An error is returned if the checksum of a chunk or of the data does not match the reference,
+// for example when the blob is being published again
+func ReadBlob(ctx context.Context, cli client.Client, namespace string, ref BlobRef) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, chunk := range ref.Chunks {
+		var cm corev1.ConfigMap
+		err := cli.Get(ctx, types.NamespacedName{
+			Namespace: namespace,
+			Name:      chunk.ConfigMap,
+		}, &cm)
+		if err != nil {
+			return nil, err
+		}
+		data := cm.BinaryData[blobDataKey]
+		if checksum(data) != chunk.SHA256 {
+			return nil, fmt.Errorf("checksum of chunk %q of blob %q does not match", chunk.ConfigMap, ref.Name)
+		}
+		buf.Write(data)
+	}
+	if buf.Len() != ref.Size || checksum(buf.Bytes()) != ref.SHA256 {
+		return nil, fmt.Errorf("checksum of blob %q does not match", ref.Name)
+	}
+	return buf.Bytes(), nil
+}
+
+// DeleteBlobs deletes the blobs of the component, except the ones named in keep
+func DeleteBlobs(ctx context.Context, cli client.Client, namespace string, componentName string, keep ...string) error {
+	kept := make(map[string]bool, len(keep))
+	for _, name := range keep {
+		kept[name] = true
+	}
+	var list corev1.ConfigMapList
+	err := cli.List(ctx, &list, client.InNamespace(namespace), client.MatchingLabels{
+		BlobLabel: componentName,
+	})
+	if err != nil {
+		return err
+	}
+	for i := range list.Items {
+		if kept[list.Items[i].GetLabels()[blobNameLabel]] {
+			continue
+		}
+		err = cli.Delete(ctx, &list.Items[i])
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
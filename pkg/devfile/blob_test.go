@@ -0,0 +1,181 @@
+package devfile
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// applyClient is a fake client accepting the apply patches, not supported by the fake client,
+// by creating or replacing the object
+type applyClient struct {
+	client.Client
+}
+
+func (c applyClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if patch != client.Apply {
+		return c.Client.Patch(ctx, obj, patch, opts...)
+	}
+	var existing corev1.ConfigMap
+	err := c.Client.Get(ctx, client.ObjectKeyFromObject(obj), &existing)
+	if errors.IsNotFound(err) {
+		return c.Client.Create(ctx, obj)
+	}
+	if err != nil {
+		return err
+	}
+	obj.SetResourceVersion(existing.GetResourceVersion())
+	return c.Client.Update(ctx, obj)
+}
+
+func newApplyClient() client.Client {
+	return applyClient{fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()}
+}
+
+func TestPublishBlob(t *testing.T) {
+	tests := []struct {
+		name       string
+		size       int
+		wantChunks int
+	}{
+		{name: "empty blob", size: 0, wantChunks: 1},
+		{name: "small blob", size: 10, wantChunks: 1},
+		{name: "size of a chunk", size: blobChunkSize, wantChunks: 1},
+		{name: "one byte more than a chunk", size: blobChunkSize + 1, wantChunks: 2},
+		{name: "several chunks", size: 5 * blobChunkSize / 2, wantChunks: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			cli := newApplyClient()
+			data := bytes.Repeat([]byte("0123456789"), tt.size/10+1)[:tt.size]
+
+			ref, err := PublishBlob(ctx, cli, "ns", "my-component", "complete", data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(ref.Chunks) != tt.wantChunks {
+				t.Errorf("PublishBlob() %d chunks, want %d", len(ref.Chunks), tt.wantChunks)
+			}
+			if ref.Size != tt.size {
+				t.Errorf("PublishBlob() size = %d, want %d", ref.Size, tt.size)
+			}
+
+			got, err := ReadBlob(ctx, cli, "ns", *ref)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, data) {
+				t.Errorf("ReadBlob() returned %d bytes different from the published ones", len(got))
+			}
+		})
+	}
+}
+
+func TestPublishBlobSmaller(t *testing.T) {
+	ctx := context.Background()
+	cli := newApplyClient()
+	if _, err := PublishBlob(ctx, cli, "ns", "my-component", "complete", make([]byte, 3*blobChunkSize)); err != nil {
+		t.Fatal(err)
+	}
+	ref, err := PublishBlob(ctx, cli, "ns", "my-component", "complete", []byte("data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var list corev1.ConfigMapList
+	if err = cli.List(ctx, &list, client.InNamespace("ns")); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Items) != 1 || list.Items[0].GetName() != ref.Chunks[0].ConfigMap {
+		t.Errorf("%d configmaps after publishing a smaller blob, want only %q", len(list.Items), ref.Chunks[0].ConfigMap)
+	}
+}
+
+func TestReadBlobModified(t *testing.T) {
+	ctx := context.Background()
+	cli := newApplyClient()
+	ref, err := PublishBlob(ctx, cli, "ns", "my-component", "complete", []byte("data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(ref *BlobRef)
+	}{
+		{
+			name: "chunk checksum",
+			modify: func(ref *BlobRef) {
+				ref.Chunks[0].SHA256 = checksum([]byte("other"))
+			},
+		},
+		{
+			name: "blob checksum",
+			modify: func(ref *BlobRef) {
+				ref.SHA256 = checksum([]byte("other"))
+			},
+		},
+		{
+			name: "blob size",
+			modify: func(ref *BlobRef) {
+				ref.Size++
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			modified := *ref
+			modified.Chunks = append([]ChunkRef(nil), ref.Chunks...)
+			tt.modify(&modified)
+			if _, err := ReadBlob(ctx, cli, "ns", modified); err == nil {
+				t.Errorf("ReadBlob() succeeded with a modified reference")
+			}
+		})
+	}
+}
+
+func TestDeleteBlobs(t *testing.T) {
+	ctx := context.Background()
+	cli := newApplyClient()
+	for _, name := range []string{"complete", "incremental", "previous"} {
+		if _, err := PublishBlob(ctx, cli, "ns", "my-component", name, []byte(name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := PublishBlob(ctx, cli, "ns", "other", "previous", []byte("other")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := DeleteBlobs(ctx, cli, "ns", "my-component", "complete", "incremental"); err != nil {
+		t.Fatal(err)
+	}
+
+	var list corev1.ConfigMapList
+	if err := cli.List(ctx, &list, client.InNamespace("ns")); err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]bool{}
+	for _, cm := range list.Items {
+		got[cm.GetName()] = true
+	}
+	want := map[string]bool{
+		"my-component-blob-complete-0":    true,
+		"my-component-blob-incremental-0": true,
+		"other-blob-previous-0":           true,
+	}
+	if len(got) != len(want) {
+		t.Errorf("configmaps after DeleteBlobs() = %v, want %v", got, want)
+	}
+	for name := range want {
+		if !got[name] {
+			t.Errorf("configmap %q deleted", name)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package devfile
+
+import "github.com/ghodss/yaml"
+
+// setYAMLData sets the YAML serialization of value into data[key]
+func setYAMLData(data map[string]string, key string, value interface{}) error {
+	content, err := yaml.Marshal(value)
+	if err != nil {
+		return err
+	}
+	data[key] = string(content)
+	return nil
+}
+
+// getYAMLData unserializes data[key] into value, if the key exists
+func getYAMLData(data map[string]string, key string, value interface{}) error {
+	content, ok := data[key]
+	if !ok {
+		return nil
+	}
+	return yaml.Unmarshal([]byte(content), value)
+}
@@ -0,0 +1,56 @@
+package devfile
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestYAMLData(t *testing.T) {
+	startedAt := metav1.NewTime(time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC).Local())
+	tests := []struct {
+		name  string
+		value *CommandResult
+	}{
+		{
+			name: "command result",
+			value: &CommandResult{
+				Command:    "build",
+				ExitCode:   1,
+				Output:     "error: line 1\n",
+				StartedAt:  startedAt,
+				FinishedAt: metav1.NewTime(startedAt.Add(time.Minute)),
+			},
+		},
+		{
+			name: "nil value",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := map[string]string{}
+			if err := setYAMLData(data, "lastCommand", tt.value); err != nil {
+				t.Fatal(err)
+			}
+			var got *CommandResult
+			if err := getYAMLData(data, "lastCommand", &got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.value) {
+				t.Errorf("getYAMLData() = %+v, want %+v", got, tt.value)
+			}
+		})
+	}
+}
+
+func TestGetYAMLDataMissingKey(t *testing.T) {
+	value := []string{"unchanged"}
+	if err := getYAMLData(map[string]string{}, "kubernetesConflicts", &value); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(value, []string{"unchanged"}) {
+		t.Errorf("getYAMLData() modified the value to %v for a missing key", value)
+	}
+}
@@ -2,8 +2,9 @@ package devfile
 
 import (
 	"context"
+	"fmt"
 	"os"
-	"strconv"
+	"sync"
 	"time"
 
 	devfilev1 "github.com/devfile/api/v2/pkg/apis/workspaces/v1alpha2"
@@ -18,7 +19,9 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/serializer"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/util/retry"
 	"k8s.io/kubectl/pkg/scheme"
+	"k8s.io/utils/pointer"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	pkgclient "sigs.k8s.io/controller-runtime/pkg/client"
@@ -27,39 +30,257 @@ import (
 )
 
 const (
-	// devfileSpecName is the name of the configmap containing the spec (the devfile)
-	devfileSpecName = "devfile-spec"
+	// devfileSpecSuffix is the suffix of the name of the configmap containing the spec (the devfile)
+	devfileSpecSuffix = "-devfile-spec"
 
-	// devfileStatusName is the name of the configmap containing the status
-	devfileStatusName = "devfile-status"
+	// devfileStatusSuffix is the suffix of the name of the configmap containing the status
+	devfileStatusSuffix = "-devfile-status"
 
 	// DevfileSpecLabel is the label set to configmap
 	DevfileSpecLabel = "devfile-spec"
 
 	// DevfileStatusLabel is the label set to configmap
 	DevfileStatusLabel = "devfile-status"
+
+	// ComponentLabel is the label set to the resources created from Kubernetes components
+	ComponentLabel = "devfile-component"
 )
 
+// GetSpecConfigMapName returns the name of the configmap containing the spec of the component
+func GetSpecConfigMapName(componentName string) string {
+	return componentName + devfileSpecSuffix
+}
+
+// GetSpecOwnerReference returns an owner reference to the spec configmap, so the status and the resources
+// created for the component are deleted with the spec
+func GetSpecOwnerReference(spec *corev1.ConfigMap) metav1.OwnerReference {
+	apiVersion, kind := corev1.SchemeGroupVersion.WithKind("ConfigMap").ToAPIVersionAndKind()
+	return metav1.OwnerReference{
+		APIVersion:         apiVersion,
+		Kind:               kind,
+		Name:               spec.GetName(),
+		UID:                spec.GetUID(),
+		Controller:         pointer.Bool(true),
+		BlockOwnerDeletion: pointer.Bool(true),
+	}
+}
+
+// GetStatusConfigMapName returns the name of the configmap containing the status of the component
+func GetStatusConfigMapName(componentName string) string {
+	return componentName + devfileStatusSuffix
+}
+
 type Status string
 
 const (
-	StatusWaitDeployment       Status = "WaitDeployment"
-	StatusWaitBindings         Status = "WaitBindings"
-	StatusPodRunning           Status = "PodRunning"
-	StatusFilesSynced          Status = "FilesSynced"
-	StatusBuildCommandExecuted Status = "BuildCommandExecuted"
-	StatusRunCommandRunning    Status = "RunCommandRunning"
-	StatusReady                Status = "Ready"
+	StatusWaitDeployment        Status = "WaitDeployment"
+	StatusWaitBindings          Status = "WaitBindings"
+	StatusPodRunning            Status = "PodRunning"
+	StatusPostStartFailed       Status = "PostStartFailed"
+	StatusFilesSynced           Status = "FilesSynced"
+	StatusBuildCommandExecuted  Status = "BuildCommandExecuted"
+	StatusBuildFailed           Status = "BuildFailed"
+	StatusRunCommandRunning     Status = "RunCommandRunning"
+	StatusRunCommandHotReloaded Status = "RunCommandHotReloaded"
+	StatusRunCommandExited      Status = "RunCommandExited"
+	StatusRunCommandBackOff     Status = "RunCommandBackOff"
+	StatusReady                 Status = "Ready"
+)
+
+// RestartPolicy defines when the run command is restarted after it exited
+type RestartPolicy string
+
+const (
+	RestartPolicyAlways    RestartPolicy = "always"
+	RestartPolicyOnFailure RestartPolicy = "on-failure"
+	RestartPolicyNever     RestartPolicy = "never"
 )
 
+// DefaultRestartPolicy is the restart policy used when none is defined in the spec
+const DefaultRestartPolicy = RestartPolicyOnFailure
+
+// ParseRestartPolicy returns the restart policy from its name
+func ParseRestartPolicy(name string) (RestartPolicy, error) {
+	switch policy := RestartPolicy(name); policy {
+	case RestartPolicyAlways, RestartPolicyOnFailure, RestartPolicyNever:
+		return policy, nil
+	}
+	return "", fmt.Errorf("invalid restart policy %q, must be one of %s, %s or %s", name, RestartPolicyAlways, RestartPolicyOnFailure, RestartPolicyNever)
+}
+
 type ConfigMapContent struct {
-	Devfile             string
-	CompleteSyncModTime int64
+	// Generation must be incremented every time the spec is modified
+	Generation int64
+	Devfile    string
+	// CompleteSyncGeneration is the digest of the manifest of the complete archive
+	CompleteSyncGeneration string
+	// IncrementalSyncGeneration is the digest of the manifest of the sources, when files have been modified
+	// or deleted since the complete archive has been created, empty if no file has been modified
+	IncrementalSyncGeneration string
+	// CompleteArchive references the complete archive of the sources published into the cluster
+	CompleteArchive *BlobRef
+	// IncrementalArchive references the archive of the files modified since the complete archive has been created
+	IncrementalArchive *BlobRef
+	// Manifests references the manifests of the sources published into the cluster, indexed by generation
+	Manifests map[string]BlobRef
+	// DeletedFiles are the files deleted since the complete archive has been created
+	DeletedFiles []string
+	// Variables contains the values of the devfile variables passed by the user,
+	// overriding the values defined in the devfile
+	Variables map[string]string
+	// RestartPolicy defines when the run command is restarted after it exited
+	RestartPolicy RestartPolicy
+	// Debug is true to execute the default debug command instead of the default run command
+	Debug bool
+	// PinnedPorts are the local ports requested by the user for the endpoints, indexed by endpoint name
+	PinnedPorts map[string]int
+	// PreviousPorts are the local ports assigned to the endpoints during the previous session, indexed by endpoint name
+	PreviousPorts map[string]int
+}
+
+// SpecContent is the content of the spec configmap, as read by the controller
+type SpecContent struct {
+	// Generation is incremented by the client every time the spec is modified
+	Generation    int64
+	Devfile       *parser.DevfileObj
+	ComponentName string
+	// CompleteSyncGeneration is the digest of the manifest of the complete archive
+	CompleteSyncGeneration string
+	// IncrementalSyncGeneration is the digest of the manifest of the sources, when files have been modified
+	// or deleted since the complete archive has been created
+	IncrementalSyncGeneration string
+	// CompleteArchive references the complete archive of the sources published into the cluster
+	CompleteArchive *BlobRef
+	// IncrementalArchive references the archive of the files modified since the complete archive has been created
+	IncrementalArchive *BlobRef
+	// Manifests references the manifests of the sources published into the cluster, indexed by generation
+	Manifests map[string]BlobRef
+	// DeletedFiles are the files deleted since the complete archive has been created
+	DeletedFiles []string
+	// KubernetesManifests contains the manifests of the Kubernetes components referenced by URI,
+	// indexed by component name
+	KubernetesManifests map[string]string
+	// Variables contains the resolved values of the devfile variables
+	Variables map[string]string
+	// RestartPolicy defines when the run command is restarted after it exited
+	RestartPolicy RestartPolicy
+	// Debug is true to execute the default debug command instead of the default run command
+	Debug bool
+	// PinnedPorts are the local ports requested by the user for the endpoints, indexed by endpoint name
+	PinnedPorts map[string]int
+	// PreviousPorts are the local ports assigned to the endpoints during the previous session, indexed by endpoint name
+	PreviousPorts map[string]int
 }
 
 type StatusContent struct {
-	Status                Status
-	SyncedCompleteModTime *int64
+	Status Status
+	// ObservedGeneration is the generation of the spec last processed by the controller
+	ObservedGeneration int64
+	// Conditions are set depending on Status, and are ignored by SetStatus
+	Conditions []metav1.Condition
+	// SyncedCompleteGeneration is the generation of the last complete archive synced
+	SyncedCompleteGeneration *string
+	// SyncedIncrementalGeneration is the generation of the last incremental archive synced
+	SyncedIncrementalGeneration *string
+	// SyncedPod is the pod to which the sources have been synced, and in which the build and run commands are executed
+	SyncedPod *SyncedPod
+	// KubernetesComponents is the inventory of the resources created from Kubernetes components
+	KubernetesComponents []KubernetesObject
+	// KubernetesConflicts contains the field conflicts detected when applying Kubernetes components
+	KubernetesConflicts []string
+	// LastCommand is the result of the last build or run command terminated
+	LastCommand *CommandResult
+	// RestartCount is the number of times the run command has been restarted after it exited
+	RestartCount *int
+	// SubCommands is the progress of the sub-commands of the last composite command executed
+	SubCommands []SubCommandStatus
+	// PostStartPodUID is the UID of the last pod in which the postStart commands have been executed
+	PostStartPodUID string
+	// ForwardedPorts are the ports of the containers forwarded to local ports
+	ForwardedPorts []ForwardedPort
+	// LastTest is the result of the last test command executed by the client.
+	// It is written with SetTestResult, and ignored by SetStatus
+	LastTest *CommandResult
+}
+
+type SubCommandState string
+
+const (
+	SubCommandPending   SubCommandState = "Pending"
+	SubCommandRunning   SubCommandState = "Running"
+	SubCommandSucceeded SubCommandState = "Succeeded"
+	SubCommandFailed    SubCommandState = "Failed"
+)
+
+// SubCommandStatus is the progress of a sub-command of a composite command
+type SubCommandStatus struct {
+	// Command is the id of the devfile command
+	Command string          `json:"command"`
+	State   SubCommandState `json:"state"`
+	// ExitCode is the exit code of the command, when terminated
+	ExitCode *int `json:"exitCode,omitempty"`
+}
+
+// SyncedPod identifies a pod, and the restarts of its containers
+type SyncedPod struct {
+	UID string `json:"uid"`
+	// RestartCount is the sum of the restart counts of the containers of the pod
+	RestartCount int32 `json:"restartCount"`
+}
+
+// CommandResult is the result of the execution of a devfile command
+type CommandResult struct {
+	// Command is the id of the devfile command
+	Command  string `json:"command"`
+	ExitCode int    `json:"exitCode"`
+	// Output is the tail of the output of the command
+	Output     string      `json:"output,omitempty"`
+	StartedAt  metav1.Time `json:"startedAt"`
+	FinishedAt metav1.Time `json:"finishedAt"`
+}
+
+// ForwardedPort is a port of a container forwarded to a local port
+type ForwardedPort struct {
+	ContainerName string `json:"containerName"`
+	// EndpointName is the name of the endpoint, or "debug" for the debug port
+	EndpointName  string           `json:"endpointName"`
+	LocalPort     int              `json:"localPort"`
+	ContainerPort int              `json:"containerPort"`
+	State         PortForwardState `json:"state,omitempty"`
+	// Error is the error of the last attempt to forward the port, when failed
+	Error string `json:"error,omitempty"`
+}
+
+type PortForwardState string
+
+const (
+	// PortForwardConnecting is the state of a port being forwarded, or forwarded again after a failure
+	PortForwardConnecting PortForwardState = "Connecting"
+	// PortForwardListening is the state of a local port listening and connected to the pod
+	PortForwardListening PortForwardState = "Listening"
+	// PortForwardFailed is the state of a port which failed to be forwarded, or whose connection has been lost.
+	// The port is forwarded again with an exponential backoff
+	PortForwardFailed PortForwardState = "Failed"
+)
+
+// KubernetesObject identifies a resource created from a Kubernetes component
+type KubernetesObject struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+}
+
+// ParseDevfile parses and validates the devfile at path, using the values of the variables passed by the user
+func ParseDevfile(path string, variables map[string]string) (parser.DevfileObj, error) {
+	devfileObj, varWarning, err := devfile.ParseDevfileAndValidate(parser.ParserArgs{
+		Path:              path,
+		ExternalVariables: variables,
+	})
+	if err != nil {
+		return parser.DevfileObj{}, err
+	}
+	logVariableWarning(varWarning)
+	return devfileObj, nil
 }
 
 func CreateConfigMapFromDevfile(ctx context.Context, client client.Client, namespace string, componentName string, cmContent ConfigMapContent) (*corev1.ConfigMap, error) {
@@ -67,13 +288,41 @@ func CreateConfigMapFromDevfile(ctx context.Context, client client.Client, names
 	if err != nil {
 		return nil, err
 	}
+	devfileObj, err := ParseDevfile(cmContent.Devfile, cmContent.Variables)
+	if err != nil {
+		return nil, err
+	}
+	manifests, err := getKubernetesManifestsFromURI(devfileObj, cmContent.Devfile)
+	if err != nil {
+		return nil, err
+	}
+	doc := SpecDocument{
+		APIVersion:                APIVersion,
+		Generation:                cmContent.Generation,
+		Devfile:                   string(content),
+		CompleteSyncGeneration:    cmContent.CompleteSyncGeneration,
+		IncrementalSyncGeneration: cmContent.IncrementalSyncGeneration,
+		CompleteArchive:           cmContent.CompleteArchive,
+		IncrementalArchive:        cmContent.IncrementalArchive,
+		Manifests:                 cmContent.Manifests,
+		DeletedFiles:              cmContent.DeletedFiles,
+		// the devfile variables, merged with the values passed by the user
+		Variables:     devfileObj.Data.GetDevfileWorkspaceSpec().Variables,
+		RestartPolicy: cmContent.RestartPolicy,
+		Debug:         cmContent.Debug,
+		PinnedPorts:   cmContent.PinnedPorts,
+		PreviousPorts: cmContent.PreviousPorts,
+	}
+	if len(manifests) > 0 {
+		doc.KubernetesManifests = manifests
+	}
 	configMap := corev1.ConfigMap{
-		Data: map[string]string{
-			"devfile":             string(content),
-			"completeSyncModTime": strconv.FormatInt(cmContent.CompleteSyncModTime, 10),
-		},
+		Data: map[string]string{},
+	}
+	if err = setYAMLData(configMap.Data, specKey, doc); err != nil {
+		return nil, err
 	}
-	configMap.SetName(devfileSpecName)
+	configMap.SetName(GetSpecConfigMapName(componentName))
 	configMap.SetNamespace(namespace)
 	configMap.SetLabels(map[string]string{
 		DevfileSpecLabel: componentName,
@@ -97,7 +346,7 @@ func DeleteConfigMapAndWait(ctx context.Context, mgr manager.Manager, client cli
 
 	opts := metav1.ListOptions{
 		Watch:         true,
-		FieldSelector: "metadata.name=devfile-spec",
+		FieldSelector: "metadata.name=" + cm.GetName(),
 	}
 
 	watcher, err := rest.Get().
@@ -137,24 +386,87 @@ outer:
 	return nil
 }
 
-func InfoFromDevfileConfigMap(ctx context.Context, client client.Client, cm corev1.ConfigMap) (*parser.DevfileObj, string, *int64, error) {
-	content := cm.Data["devfile"]
-	devfileObj, _, err := devfile.ParseDevfileAndValidate(parser.ParserArgs{
-		Data: []byte(content),
+// GetSpec returns the content of the spec configmap of the component
+func GetSpec(ctx context.Context, client client.Client, namespace string, componentName string) (*SpecContent, error) {
+	var cm corev1.ConfigMap
+	err := client.Get(ctx, types.NamespacedName{
+		Namespace: namespace,
+		Name:      GetSpecConfigMapName(componentName),
+	}, &cm)
+	if err != nil {
+		return nil, err
+	}
+	return InfoFromDevfileConfigMap(ctx, client, cm)
+}
+
+// GetLastGeneration returns the last generation of the spec of the component, or the last generation observed
+// by the controller if greater, as the status can be left over from a previous session.
+// Zero is returned when no spec nor status exists
+func GetLastGeneration(ctx context.Context, client client.Reader, namespace string, componentName string) (int64, error) {
+	var generation int64
+	var cm corev1.ConfigMap
+	err := client.Get(ctx, types.NamespacedName{
+		Namespace: namespace,
+		Name:      GetSpecConfigMapName(componentName),
+	}, &cm)
+	if err != nil && !errors.IsNotFound(err) {
+		return 0, err
+	}
+	if err == nil {
+		// a spec written by a previous version has no generation
+		if doc, err := specDocumentFromData(cm.Data); err == nil {
+			generation = doc.Generation
+		}
+	}
+	// an unreadable status is replaced by the controller
+	status, err := GetStatus(ctx, client, namespace, componentName)
+	if err == nil && status.ObservedGeneration > generation {
+		generation = status.ObservedGeneration
+	}
+	return generation, nil
+}
+
+func InfoFromDevfileConfigMap(ctx context.Context, client client.Client, cm corev1.ConfigMap) (*SpecContent, error) {
+	doc, err := specDocumentFromData(cm.Data)
+	if err != nil {
+		return nil, err
+	}
+	devfileObj, varWarning, err := devfile.ParseDevfileAndValidate(parser.ParserArgs{
+		Data:              []byte(doc.Devfile),
+		ExternalVariables: doc.Variables,
 	})
 	if err != nil {
-		return nil, "", nil, err
+		return nil, err
 	}
-	var completeSyncModTime *int64
-	if val, ok := cm.Data["completeSyncModTime"]; ok {
-		var modTime int64
-		modTime, err = strconv.ParseInt(val, 10, 64)
+	logVariableWarning(varWarning)
+	restartPolicy := DefaultRestartPolicy
+	if doc.RestartPolicy != "" {
+		restartPolicy, err = ParseRestartPolicy(string(doc.RestartPolicy))
 		if err != nil {
-			return nil, "", nil, err
+			return nil, err
 		}
-		completeSyncModTime = &modTime
 	}
-	return &devfileObj, cm.GetLabels()[DevfileSpecLabel], completeSyncModTime, nil
+	manifests := doc.KubernetesManifests
+	if manifests == nil {
+		manifests = map[string]string{}
+	}
+	return &SpecContent{
+		Generation:                doc.Generation,
+		Devfile:                   &devfileObj,
+		ComponentName:             cm.GetLabels()[DevfileSpecLabel],
+		CompleteSyncGeneration:    doc.CompleteSyncGeneration,
+		IncrementalSyncGeneration: doc.IncrementalSyncGeneration,
+		CompleteArchive:           doc.CompleteArchive,
+		IncrementalArchive:        doc.IncrementalArchive,
+		Manifests:                 doc.Manifests,
+		DeletedFiles:              doc.DeletedFiles,
+		KubernetesManifests:       manifests,
+		Variables:                 doc.Variables,
+		RestartPolicy:             restartPolicy,
+		Debug:                     doc.Debug,
+		PinnedPorts:               doc.PinnedPorts,
+		PreviousPorts:             doc.PreviousPorts,
+	}, nil
 }
 
 // From odo/pkg/devfile
@@ -199,57 +511,197 @@ func GetKubernetesComponentsToPush(devfileObj parser.DevfileObj) ([]devfilev1.Co
 	return k8sComponents, err
 }
 
-func SetStatus(ctx context.Context, client client.Client, namespace string, componentName string, ownerRef metav1.OwnerReference, status StatusContent) error {
+// SetStatus sets the fields of status into the status of the component, keeping the other fields.
+// The status is read with reader, which must not be cached, and the write is retried if the status
+// is modified by another writer in the meantime
+func SetStatus(ctx context.Context, client client.Client, reader client.Reader, namespace string, componentName string, ownerRef metav1.OwnerReference, status StatusContent) error {
+	defer lockStatus(namespace, componentName)()
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var cm corev1.ConfigMap
+		err := reader.Get(ctx, types.NamespacedName{
+			Namespace: namespace,
+			Name:      GetStatusConfigMapName(componentName),
+		}, &cm)
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		// an unreadable status is replaced
+		oldStatus, _ := StatusFromConfigMap(&cm)
 
-	oldStatus, _ := GetStatus(ctx, client, namespace, componentName)
+		configMap, err := buildStatusConfigMap(namespace, componentName, ownerRef, mergeStatus(oldStatus, status))
+		if err != nil {
+			return err
+		}
+		// the status is not applied if it has been modified since it has been read
+		configMap.SetResourceVersion(cm.GetResourceVersion())
+		return client.Patch(ctx, configMap, pkgclient.Apply, pkgclient.FieldOwner("ododev"))
+	})
+}
 
-	configMap := corev1.ConfigMap{
-		Data: map[string]string{
-			"status": string(status.Status),
-		},
+// statusLocks contains a mutex per component, serializing the writes of the status by the controller
+var statusLocks sync.Map
+
+// lockStatus locks the status of the component, and returns the function to unlock it
+func lockStatus(namespace string, componentName string) func() {
+	value, _ := statusLocks.LoadOrStore(namespace+"/"+componentName, &sync.Mutex{})
+	mu := value.(*sync.Mutex)
+	mu.Lock()
+	return mu.Unlock
+}
+
+// mergeStatus returns the status document containing the fields of status, and the fields of oldStatus
+// for the fields not set in status
+func mergeStatus(oldStatus StatusContent, status StatusContent) StatusDocument {
+	doc := StatusDocument{
+		APIVersion:           APIVersion,
+		ObservedGeneration:   oldStatus.ObservedGeneration,
+		Phase:                oldStatus.Status,
+		Conditions:           oldStatus.Conditions,
+		SyncedPod:            oldStatus.SyncedPod,
+		KubernetesComponents: oldStatus.KubernetesComponents,
+		KubernetesConflicts:  oldStatus.KubernetesConflicts,
+		LastCommand:          oldStatus.LastCommand,
+		RestartCount:         oldStatus.RestartCount,
+		SubCommands:          oldStatus.SubCommands,
+		PostStartPodUID:      oldStatus.PostStartPodUID,
+		ForwardedPorts:       oldStatus.ForwardedPorts,
+	}
+	doc.SyncedCompleteGeneration = pointer.StringDeref(oldStatus.SyncedCompleteGeneration, "")
+	doc.SyncedIncrementalGeneration = pointer.StringDeref(oldStatus.SyncedIncrementalGeneration, "")
+
+	if status.ObservedGeneration != 0 {
+		doc.ObservedGeneration = status.ObservedGeneration
+	}
+	if status.SyncedCompleteGeneration != nil {
+		doc.SyncedCompleteGeneration = *status.SyncedCompleteGeneration
+	}
+	if status.SyncedIncrementalGeneration != nil {
+		doc.SyncedIncrementalGeneration = *status.SyncedIncrementalGeneration
+	}
+	if status.KubernetesConflicts != nil {
+		doc.KubernetesConflicts = status.KubernetesConflicts
+	}
+	if status.KubernetesComponents != nil {
+		doc.KubernetesComponents = status.KubernetesComponents
+	}
+	if status.LastCommand != nil {
+		doc.LastCommand = status.LastCommand
+	}
+	if status.SubCommands != nil {
+		doc.SubCommands = status.SubCommands
+	}
+	if status.SyncedPod != nil {
+		doc.SyncedPod = status.SyncedPod
+	}
+	if status.ForwardedPorts != nil {
+		doc.ForwardedPorts = status.ForwardedPorts
+	}
+	if status.PostStartPodUID != "" {
+		doc.PostStartPodUID = status.PostStartPodUID
 	}
-	if status.SyncedCompleteModTime != nil {
-		configMap.Data["syncedCompleteModTime"] = strconv.FormatInt(*status.SyncedCompleteModTime, 10)
-	} else if oldStatus.SyncedCompleteModTime != nil {
-		configMap.Data["syncedCompleteModTime"] = strconv.FormatInt(*oldStatus.SyncedCompleteModTime, 10)
+	if status.RestartCount != nil {
+		doc.RestartCount = status.RestartCount
 	}
+	if status.Status != "" {
+		doc.Phase = status.Status
+		setPhaseConditions(&doc.Conditions, doc.Phase, doc.LastCommand, doc.ObservedGeneration)
+	}
+
+	return doc
+}
 
+// buildStatusConfigMap returns the status configmap containing the status document
+func buildStatusConfigMap(namespace string, componentName string, ownerRef metav1.OwnerReference, doc StatusDocument) (*corev1.ConfigMap, error) {
+	configMap := corev1.ConfigMap{
+		Data: map[string]string{},
+	}
+	if err := setYAMLData(configMap.Data, statusKey, doc); err != nil {
+		return nil, err
+	}
 	apiVersion, kind := corev1.SchemeGroupVersion.WithKind("ConfigMap").ToAPIVersionAndKind()
 	configMap.TypeMeta = generator.GetTypeMeta(kind, apiVersion)
-	configMap.SetName(devfileStatusName)
+	configMap.SetName(GetStatusConfigMapName(componentName))
 	configMap.SetNamespace(namespace)
 	configMap.SetLabels(map[string]string{
 		DevfileStatusLabel: componentName,
 	})
 	configMap.SetOwnerReferences([]metav1.OwnerReference{ownerRef})
+	return &configMap, nil
+}
+
+// SetTestResult records the result of the test command in the status.
+// A dedicated field manager is used, so the result is kept when the controller updates the status.
+// The status is owned by the spec, as when it is written by the controller
+func SetTestResult(ctx context.Context, client client.Client, namespace string, componentName string, result *CommandResult) error {
+	var spec corev1.ConfigMap
+	err := client.Get(ctx, types.NamespacedName{
+		Namespace: namespace,
+		Name:      GetSpecConfigMapName(componentName),
+	}, &spec)
+	if err != nil {
+		return err
+	}
+	configMap := corev1.ConfigMap{
+		Data: map[string]string{},
+	}
+	if err := setYAMLData(configMap.Data, lastTestKey, result); err != nil {
+		return err
+	}
+	apiVersion, kind := corev1.SchemeGroupVersion.WithKind("ConfigMap").ToAPIVersionAndKind()
+	configMap.TypeMeta = generator.GetTypeMeta(kind, apiVersion)
+	configMap.SetName(GetStatusConfigMapName(componentName))
+	configMap.SetNamespace(namespace)
+	configMap.SetOwnerReferences([]metav1.OwnerReference{GetSpecOwnerReference(&spec)})
 
-	err := client.Patch(ctx, &configMap, pkgclient.Apply, pkgclient.FieldOwner("ododev"))
-	return err
+	return client.Patch(ctx, &configMap, pkgclient.Apply, pkgclient.FieldOwner("ododev-test"))
 }
 
-func GetStatus(ctx context.Context, client client.Client, namespace string, componentName string) (StatusContent, error) {
+func GetStatus(ctx context.Context, client client.Reader, namespace string, componentName string) (StatusContent, error) {
 	cmKey := types.NamespacedName{
 		Namespace: namespace,
-		Name:      devfileStatusName,
+		Name:      GetStatusConfigMapName(componentName),
 	}
 	var cm corev1.ConfigMap
 	err := client.Get(ctx, cmKey, &cm)
 	if err != nil {
 		return StatusContent{}, err
 	}
+	return StatusFromConfigMap(&cm)
+}
 
-	var syncedCompleteModTime *int64
-	if val, ok := cm.Data["syncedCompleteModTime"]; ok {
-		var modTime int64
-		modTime, err = strconv.ParseInt(val, 10, 64)
-		if err != nil {
-			return StatusContent{}, err
-		}
-		syncedCompleteModTime = &modTime
+// StatusFromConfigMap returns the status stored into the status configmap
+func StatusFromConfigMap(cm *corev1.ConfigMap) (StatusContent, error) {
+	doc, err := statusDocumentFromData(cm.Data)
+	if err != nil {
+		return StatusContent{}, err
+	}
+	var lastTest *CommandResult
+	if err := getYAMLData(cm.Data, lastTestKey, &lastTest); err != nil {
+		return StatusContent{}, err
+	}
+	var syncedCompleteGeneration, syncedIncrementalGeneration *string
+	if doc.SyncedCompleteGeneration != "" {
+		syncedCompleteGeneration = &doc.SyncedCompleteGeneration
+	}
+	if doc.SyncedIncrementalGeneration != "" {
+		syncedIncrementalGeneration = &doc.SyncedIncrementalGeneration
 	}
 	return StatusContent{
-		Status:                Status(cm.Data["status"]),
-		SyncedCompleteModTime: syncedCompleteModTime,
+		Status:                      doc.Phase,
+		ObservedGeneration:          doc.ObservedGeneration,
+		Conditions:                  doc.Conditions,
+		SyncedCompleteGeneration:    syncedCompleteGeneration,
+		SyncedIncrementalGeneration: syncedIncrementalGeneration,
+		SyncedPod:                   doc.SyncedPod,
+		KubernetesComponents:        doc.KubernetesComponents,
+		KubernetesConflicts:         doc.KubernetesConflicts,
+		LastCommand:                 doc.LastCommand,
+		RestartCount:                doc.RestartCount,
+		SubCommands:                 doc.SubCommands,
+		PostStartPodUID:             doc.PostStartPodUID,
+		ForwardedPorts:              doc.ForwardedPorts,
+		LastTest:                    lastTest,
 	}, nil
 }
 
@@ -269,7 +721,7 @@ func WatchStatus(
 
 	opts := metav1.ListOptions{
 		Watch:         true,
-		FieldSelector: "metadata.name=devfile-status",
+		FieldSelector: "metadata.name=" + GetStatusConfigMapName(componentName),
 	}
 
 	watcher, err := rest.Get().
@@ -0,0 +1,106 @@
+package devfile
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestParseRestartPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    RestartPolicy
+		wantErr bool
+	}{
+		{name: "always", want: RestartPolicyAlways},
+		{name: "on-failure", want: RestartPolicyOnFailure},
+		{name: "never", want: RestartPolicyNever},
+		{name: "Always", wantErr: true},
+		{name: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRestartPolicy(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRestartPolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseRestartPolicy() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// newDocumentConfigMap returns a configmap in the namespace "ns" containing the document at key
+func newDocumentConfigMap(t *testing.T, name string, key string, doc interface{}) *corev1.ConfigMap {
+	t.Helper()
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "ns",
+		},
+		Data: map[string]string{},
+	}
+	if err := setYAMLData(cm.Data, key, doc); err != nil {
+		t.Fatal(err)
+	}
+	return cm
+}
+
+func TestGetLastGeneration(t *testing.T) {
+	specName := GetSpecConfigMapName("my-component")
+	statusName := GetStatusConfigMapName("my-component")
+	tests := []struct {
+		name     string
+		existing []client.Object
+		want     int64
+	}{
+		{
+			name: "no spec nor status",
+			want: 0,
+		},
+		{
+			name: "generation of the spec",
+			existing: []client.Object{
+				newDocumentConfigMap(t, specName, specKey, SpecDocument{APIVersion: APIVersion, Generation: 4}),
+				newDocumentConfigMap(t, statusName, statusKey, StatusDocument{APIVersion: APIVersion, ObservedGeneration: 3}),
+			},
+			want: 4,
+		},
+		{
+			name: "generation observed in a status left over",
+			existing: []client.Object{
+				newDocumentConfigMap(t, specName, specKey, SpecDocument{APIVersion: APIVersion, Generation: 1}),
+				newDocumentConfigMap(t, statusName, statusKey, StatusDocument{APIVersion: APIVersion, ObservedGeneration: 7}),
+			},
+			want: 7,
+		},
+		{
+			name: "spec written by a previous version",
+			existing: []client.Object{
+				&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Name: specName, Namespace: "ns"},
+					Data:       map[string]string{"devfile": "schemaVersion: 2.2.0"},
+				},
+			},
+			want: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(tt.existing...).Build()
+			got, err := GetLastGeneration(context.Background(), cli, "ns", "my-component")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("GetLastGeneration() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
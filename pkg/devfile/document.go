@@ -0,0 +1,213 @@
+package devfile
+
+import (
+	"fmt"
+
+	"github.com/ghodss/yaml"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// APIVersion is the version of the spec and status documents
+	APIVersion = "ododev.feloy.github.io/v1alpha1"
+
+	// specKey is the key of the spec configmap containing the spec document
+	specKey = "spec.yaml"
+	// statusKey is the key of the status configmap containing the status document
+	statusKey = "status.yaml"
+	// lastTestKey is the key of the status configmap containing the result of the last test command.
+	// It is written by the client with a dedicated field manager, outside of the status document
+	lastTestKey = "lastTest"
+)
+
+// SpecDocument is the spec of a component, stored in the spec configmap
+type SpecDocument struct {
+	APIVersion string `json:"apiVersion"`
+	// Generation is incremented by the client every time the spec is modified
+	Generation int64 `json:"generation,omitempty"`
+	// Devfile is the content of the devfile
+	Devfile string `json:"devfile"`
+	// KubernetesManifests contains the manifests of the Kubernetes components referenced by URI,
+	// indexed by component name
+	KubernetesManifests map[string]string `json:"kubernetesManifests,omitempty"`
+	// CompleteSyncGeneration is the digest of the manifest of the complete archive
+	CompleteSyncGeneration string `json:"completeSyncGeneration,omitempty"`
+	// IncrementalSyncGeneration is the digest of the manifest of the sources, when files have been modified
+	// or deleted since the complete archive has been created
+	IncrementalSyncGeneration string `json:"incrementalSyncGeneration,omitempty"`
+	// CompleteArchive references the complete archive of the sources published into the cluster
+	CompleteArchive *BlobRef `json:"completeArchive,omitempty"`
+	// IncrementalArchive references the archive of the files modified since the complete archive has been created
+	IncrementalArchive *BlobRef `json:"incrementalArchive,omitempty"`
+	// Manifests references the manifests of the sources published into the cluster, indexed by generation
+	Manifests map[string]BlobRef `json:"manifests,omitempty"`
+	// DeletedFiles are the files deleted since the complete archive has been created
+	DeletedFiles []string `json:"deletedFiles,omitempty"`
+	// Variables contains the resolved values of the devfile variables
+	Variables map[string]string `json:"variables,omitempty"`
+	// RestartPolicy defines when the run command is restarted after it exited
+	RestartPolicy RestartPolicy `json:"restartPolicy,omitempty"`
+	// Debug is true to execute the default debug command instead of the default run command
+	Debug bool `json:"debug,omitempty"`
+	// PinnedPorts are the local ports requested by the user for the endpoints, indexed by endpoint name
+	PinnedPorts map[string]int `json:"pinnedPorts,omitempty"`
+	// PreviousPorts are the local ports assigned to the endpoints during the previous session, indexed by endpoint name
+	PreviousPorts map[string]int `json:"previousPorts,omitempty"`
+}
+
+// StatusDocument is the status of a component, stored in the status configmap
+type StatusDocument struct {
+	APIVersion string `json:"apiVersion"`
+	// ObservedGeneration is the generation of the spec last processed by the controller
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Phase is the step at which the spec is being reconciled
+	Phase      Status             `json:"phase,omitempty"`
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// SyncedCompleteGeneration is the generation of the last complete archive synced
+	SyncedCompleteGeneration string `json:"syncedCompleteGeneration,omitempty"`
+	// SyncedIncrementalGeneration is the generation of the last incremental archive synced
+	SyncedIncrementalGeneration string `json:"syncedIncrementalGeneration,omitempty"`
+	// SyncedPod is the pod to which the sources have been synced
+	SyncedPod *SyncedPod `json:"syncedPod,omitempty"`
+	// KubernetesComponents is the inventory of the resources created from Kubernetes components
+	KubernetesComponents []KubernetesObject `json:"kubernetesComponents,omitempty"`
+	// KubernetesConflicts contains the field conflicts detected when applying Kubernetes components
+	KubernetesConflicts []string `json:"kubernetesConflicts,omitempty"`
+	// LastCommand is the result of the last build or run command terminated
+	LastCommand *CommandResult `json:"lastCommand,omitempty"`
+	// RestartCount is the number of times the run command has been restarted after it exited
+	RestartCount *int `json:"restartCount,omitempty"`
+	// SubCommands is the progress of the sub-commands of the last composite command executed
+	SubCommands []SubCommandStatus `json:"subCommands,omitempty"`
+	// PostStartPodUID is the UID of the last pod in which the postStart commands have been executed
+	PostStartPodUID string `json:"postStartPodUID,omitempty"`
+	// ForwardedPorts are the ports of the containers forwarded to local ports
+	ForwardedPorts []ForwardedPort `json:"forwardedPorts,omitempty"`
+}
+
+// specDocumentFromData returns the spec document stored in the data of the spec configmap,
+// converted when the configmap has been written by a previous version
+func specDocumentFromData(data map[string]string) (*SpecDocument, error) {
+	content, ok := data[specKey]
+	if !ok {
+		return specDocumentFromLegacyData(data)
+	}
+	var doc SpecDocument
+	err := yaml.Unmarshal([]byte(content), &doc)
+	if err != nil {
+		return nil, err
+	}
+	if doc.APIVersion != APIVersion {
+		return nil, fmt.Errorf("unsupported version %q of the spec, expected %q", doc.APIVersion, APIVersion)
+	}
+	return &doc, nil
+}
+
+// specDocumentFromLegacyData converts the spec written by a previous version, with one key per field,
+// into a spec document. No archive of the sources is referenced, the sources are synced again completely
+// when the client publishes them
+func specDocumentFromLegacyData(data map[string]string) (*SpecDocument, error) {
+	content, ok := data["devfile"]
+	if !ok {
+		return nil, fmt.Errorf("no %q key in the spec", specKey)
+	}
+	doc := SpecDocument{
+		APIVersion:             APIVersion,
+		Devfile:                content,
+		CompleteSyncGeneration: data["completeSyncModTime"],
+	}
+	return &doc, nil
+}
+
+// statusDocumentFromData returns the status document stored in the data of the status configmap,
+// converted when the configmap has been written by a previous version
+func statusDocumentFromData(data map[string]string) (*StatusDocument, error) {
+	content, ok := data[statusKey]
+	if !ok {
+		return statusDocumentFromLegacyData(data), nil
+	}
+	var doc StatusDocument
+	err := yaml.Unmarshal([]byte(content), &doc)
+	if err != nil {
+		return nil, err
+	}
+	if doc.APIVersion != APIVersion {
+		return nil, fmt.Errorf("unsupported version %q of the status, expected %q", doc.APIVersion, APIVersion)
+	}
+	return &doc, nil
+}
+
+// statusDocumentFromLegacyData converts the status written by a previous version, with one key per field,
+// into a status document. The modification time of the complete archive synced, used before generations
+// were digests, never matches a generation, so the sources are synced again completely
+func statusDocumentFromLegacyData(data map[string]string) *StatusDocument {
+	doc := StatusDocument{
+		APIVersion:               APIVersion,
+		Phase:                    Status(data["status"]),
+		SyncedCompleteGeneration: data["syncedCompleteModTime"],
+	}
+	return &doc
+}
+
+// Types of the conditions of the status
+const (
+	ConditionDeploymentAvailable = "DeploymentAvailable"
+	ConditionBindingsInjected    = "BindingsInjected"
+	ConditionSynced              = "Synced"
+	ConditionBuilt               = "Built"
+	ConditionRunning             = "Running"
+)
+
+// setPhaseConditions sets the conditions known when the reconciliation reaches the phase.
+// The transition time of a condition is changed only when its status changes
+func setPhaseConditions(conditions *[]metav1.Condition, phase Status, lastCommand *CommandResult, observedGeneration int64) {
+	set := func(conditionType string, status metav1.ConditionStatus, reason string, message string) {
+		meta.SetStatusCondition(conditions, metav1.Condition{
+			Type:               conditionType,
+			Status:             status,
+			Reason:             reason,
+			Message:            message,
+			ObservedGeneration: observedGeneration,
+		})
+	}
+	commandMessage := func(format string) string {
+		if lastCommand == nil {
+			return ""
+		}
+		return fmt.Sprintf(format, lastCommand.Command, lastCommand.ExitCode)
+	}
+
+	switch phase {
+	case StatusWaitDeployment:
+		set(ConditionDeploymentAvailable, metav1.ConditionFalse, "DeploymentUnavailable", "waiting for the deployment to have an available replica")
+		set(ConditionSynced, metav1.ConditionFalse, "PodUnavailable", "no pod to synchronize the sources to")
+		set(ConditionBuilt, metav1.ConditionFalse, "PodUnavailable", "no pod to execute the build command in")
+		set(ConditionRunning, metav1.ConditionFalse, "PodUnavailable", "no pod to execute the run command in")
+	case StatusWaitBindings:
+		set(ConditionDeploymentAvailable, metav1.ConditionTrue, "DeploymentAvailable", "")
+		set(ConditionBindingsInjected, metav1.ConditionFalse, "InjectionPending", "waiting for the service bindings to be injected into the deployment")
+	case StatusPodRunning:
+		set(ConditionDeploymentAvailable, metav1.ConditionTrue, "DeploymentAvailable", "")
+		set(ConditionBindingsInjected, metav1.ConditionTrue, "BindingsInjected", "")
+	case StatusPostStartFailed:
+		set(ConditionRunning, metav1.ConditionFalse, "PostStartFailed", commandMessage("postStart command %q exited with code %d"))
+	case StatusFilesSynced:
+		set(ConditionSynced, metav1.ConditionTrue, "FilesSynced", "")
+		set(ConditionBuilt, metav1.ConditionFalse, "Building", "executing the build command")
+	case StatusBuildCommandExecuted:
+		set(ConditionBuilt, metav1.ConditionTrue, "BuildSucceeded", "")
+	case StatusBuildFailed:
+		set(ConditionBuilt, metav1.ConditionFalse, "BuildFailed", commandMessage("build command %q exited with code %d"))
+		set(ConditionRunning, metav1.ConditionFalse, "BuildFailed", "")
+	case StatusRunCommandRunning:
+		set(ConditionRunning, metav1.ConditionTrue, "Running", "")
+	case StatusRunCommandHotReloaded:
+		set(ConditionSynced, metav1.ConditionTrue, "HotReloaded", "the sources are synchronized without restarting the run command")
+		set(ConditionRunning, metav1.ConditionTrue, "HotReloaded", "")
+	case StatusRunCommandExited:
+		set(ConditionRunning, metav1.ConditionFalse, "Exited", commandMessage("run command %q exited with code %d"))
+	case StatusRunCommandBackOff:
+		set(ConditionRunning, metav1.ConditionFalse, "BackOff", commandMessage("run command %q exited with code %d, restarting"))
+	}
+}
@@ -0,0 +1,265 @@
+package devfile
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSpecDocumentFromData(t *testing.T) {
+	current := SpecDocument{
+		APIVersion:             APIVersion,
+		Generation:             3,
+		Devfile:                "schemaVersion: 2.2.0",
+		CompleteSyncGeneration: "digest",
+		CompleteArchive:        &BlobRef{Name: "archive", SHA256: "sha", Size: 10, Chunks: []ChunkRef{{ConfigMap: "archive-0", SHA256: "sha"}}},
+		DeletedFiles:           []string{"a"},
+	}
+	currentData := map[string]string{}
+	if err := setYAMLData(currentData, specKey, current); err != nil {
+		t.Fatal(err)
+	}
+	otherVersion := current
+	otherVersion.APIVersion = "ododev.feloy.github.io/v2"
+	otherVersionData := map[string]string{}
+	if err := setYAMLData(otherVersionData, specKey, otherVersion); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		data    map[string]string
+		want    *SpecDocument
+		wantErr bool
+	}{
+		{
+			name: "current version",
+			data: currentData,
+			want: &current,
+		},
+		{
+			name: "previous version",
+			data: map[string]string{
+				"devfile":             "schemaVersion: 2.2.0",
+				"completeSyncModTime": "1650000000",
+			},
+			want: &SpecDocument{
+				APIVersion:             APIVersion,
+				Devfile:                "schemaVersion: 2.2.0",
+				CompleteSyncGeneration: "1650000000",
+			},
+		},
+		{
+			name: "previous version without sync",
+			data: map[string]string{
+				"devfile": "schemaVersion: 2.2.0",
+			},
+			want: &SpecDocument{
+				APIVersion: APIVersion,
+				Devfile:    "schemaVersion: 2.2.0",
+			},
+		},
+		{
+			name:    "unsupported version",
+			data:    otherVersionData,
+			wantErr: true,
+		},
+		{
+			name:    "no devfile",
+			data:    map[string]string{},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := specDocumentFromData(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("specDocumentFromData() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("specDocumentFromData() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatusDocumentFromData(t *testing.T) {
+	restartCount := 2
+	current := StatusDocument{
+		APIVersion:               APIVersion,
+		ObservedGeneration:       3,
+		Phase:                    StatusRunCommandRunning,
+		SyncedCompleteGeneration: "digest",
+		RestartCount:             &restartCount,
+	}
+	currentData := map[string]string{}
+	if err := setYAMLData(currentData, statusKey, current); err != nil {
+		t.Fatal(err)
+	}
+	otherVersion := current
+	otherVersion.APIVersion = "ododev.feloy.github.io/v2"
+	otherVersionData := map[string]string{}
+	if err := setYAMLData(otherVersionData, statusKey, otherVersion); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		data    map[string]string
+		want    *StatusDocument
+		wantErr bool
+	}{
+		{
+			name: "current version",
+			data: currentData,
+			want: &current,
+		},
+		{
+			name: "previous version",
+			data: map[string]string{
+				"status":                "RunCommandRunning",
+				"syncedCompleteModTime": "1650000000",
+				"restartCount":          "2",
+			},
+			want: &StatusDocument{
+				APIVersion:               APIVersion,
+				Phase:                    StatusRunCommandRunning,
+				SyncedCompleteGeneration: "1650000000",
+			},
+		},
+		{
+			name: "empty",
+			data: map[string]string{},
+			want: &StatusDocument{
+				APIVersion: APIVersion,
+			},
+		},
+		{
+			name:    "unsupported version",
+			data:    otherVersionData,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := statusDocumentFromData(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("statusDocumentFromData() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("statusDocumentFromData() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetPhaseConditions(t *testing.T) {
+	exited := &CommandResult{Command: "run", ExitCode: 1}
+	tests := []struct {
+		name        string
+		phases      []Status
+		lastCommand *CommandResult
+		// want are the status and reason of the conditions, indexed by type
+		want map[string][2]string
+	}{
+		{
+			name:   "waiting for the deployment",
+			phases: []Status{StatusWaitDeployment},
+			want: map[string][2]string{
+				ConditionDeploymentAvailable: {"False", "DeploymentUnavailable"},
+				ConditionSynced:              {"False", "PodUnavailable"},
+				ConditionBuilt:               {"False", "PodUnavailable"},
+				ConditionRunning:             {"False", "PodUnavailable"},
+			},
+		},
+		{
+			name:   "running",
+			phases: []Status{StatusWaitDeployment, StatusPodRunning, StatusFilesSynced, StatusBuildCommandExecuted, StatusRunCommandRunning},
+			want: map[string][2]string{
+				ConditionDeploymentAvailable: {"True", "DeploymentAvailable"},
+				ConditionBindingsInjected:    {"True", "BindingsInjected"},
+				ConditionSynced:              {"True", "FilesSynced"},
+				ConditionBuilt:               {"True", "BuildSucceeded"},
+				ConditionRunning:             {"True", "Running"},
+			},
+		},
+		{
+			name:        "run command exited",
+			phases:      []Status{StatusPodRunning, StatusFilesSynced, StatusBuildCommandExecuted, StatusRunCommandRunning, StatusRunCommandExited},
+			lastCommand: exited,
+			want: map[string][2]string{
+				ConditionDeploymentAvailable: {"True", "DeploymentAvailable"},
+				ConditionBindingsInjected:    {"True", "BindingsInjected"},
+				ConditionSynced:              {"True", "FilesSynced"},
+				ConditionBuilt:               {"True", "BuildSucceeded"},
+				ConditionRunning:             {"False", "Exited"},
+			},
+		},
+		{
+			name:        "build failed",
+			phases:      []Status{StatusPodRunning, StatusFilesSynced, StatusBuildFailed},
+			lastCommand: exited,
+			want: map[string][2]string{
+				ConditionDeploymentAvailable: {"True", "DeploymentAvailable"},
+				ConditionBindingsInjected:    {"True", "BindingsInjected"},
+				ConditionSynced:              {"True", "FilesSynced"},
+				ConditionBuilt:               {"False", "BuildFailed"},
+				ConditionRunning:             {"False", "BuildFailed"},
+			},
+		},
+		{
+			name:   "hot reloaded",
+			phases: []Status{StatusPodRunning, StatusRunCommandHotReloaded},
+			want: map[string][2]string{
+				ConditionDeploymentAvailable: {"True", "DeploymentAvailable"},
+				ConditionBindingsInjected:    {"True", "BindingsInjected"},
+				ConditionSynced:              {"True", "HotReloaded"},
+				ConditionRunning:             {"True", "HotReloaded"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var conditions []metav1.Condition
+			for _, phase := range tt.phases {
+				setPhaseConditions(&conditions, phase, tt.lastCommand, 2)
+			}
+			got := map[string][2]string{}
+			for _, c := range conditions {
+				got[c.Type] = [2]string{string(c.Status), c.Reason}
+				if c.ObservedGeneration != 2 {
+					t.Errorf("condition %q observed generation = %d, want 2", c.Type, c.ObservedGeneration)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("conditions = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetPhaseConditionsTransitionTime(t *testing.T) {
+	transition := metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second))
+	conditions := []metav1.Condition{
+		{Type: ConditionRunning, Status: metav1.ConditionTrue, Reason: "Running", LastTransitionTime: transition},
+		{Type: ConditionBuilt, Status: metav1.ConditionFalse, Reason: "Building", LastTransitionTime: transition},
+	}
+	setPhaseConditions(&conditions, StatusRunCommandHotReloaded, nil, 1)
+	setPhaseConditions(&conditions, StatusBuildCommandExecuted, nil, 1)
+
+	if c := meta.FindStatusCondition(conditions, ConditionRunning); !c.LastTransitionTime.Equal(&transition) {
+		t.Errorf("transition time of %q changed without a change of status", c.Type)
+	}
+	if c := meta.FindStatusCondition(conditions, ConditionBuilt); c.LastTransitionTime.Equal(&transition) {
+		t.Errorf("transition time of %q not changed with a change of status", c.Type)
+	}
+}
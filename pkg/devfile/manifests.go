@@ -0,0 +1,58 @@
+package devfile
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	devfilev1 "github.com/devfile/api/v2/pkg/apis/workspaces/v1alpha2"
+	"github.com/devfile/library/pkg/devfile/parser"
+	"github.com/devfile/library/pkg/devfile/parser/data/v2/common"
+)
+
+// getKubernetesManifestsFromURI returns the manifests of the Kubernetes components
+// referenced by URI in the devfile, indexed by component name.
+// Relative URIs are relative to the directory of the devfile
+func getKubernetesManifestsFromURI(devfileObj parser.DevfileObj, devfilePath string) (map[string]string, error) {
+	k8sComponents, err := devfileObj.Data.GetComponents(common.DevfileOptions{
+		ComponentOptions: common.ComponentOptions{ComponentType: devfilev1.KubernetesComponentType},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string]string{}
+	for _, component := range k8sComponents {
+		if component.Kubernetes == nil || component.Kubernetes.Uri == "" {
+			continue
+		}
+		manifest, err := loadURI(filepath.Dir(devfilePath), component.Kubernetes.Uri)
+		if err != nil {
+			return nil, fmt.Errorf("loading manifest of component %q: %w", component.Name, err)
+		}
+		result[component.Name] = string(manifest)
+	}
+	return result, nil
+}
+
+func loadURI(dir string, uri string) ([]byte, error) {
+	u, err := url.Parse(uri)
+	if err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+		resp, err := http.Get(uri)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("unexpected status %q getting %s", resp.Status, uri)
+		}
+		return io.ReadAll(resp.Body)
+	}
+	if !filepath.IsAbs(uri) {
+		uri = filepath.Join(dir, uri)
+	}
+	return os.ReadFile(uri)
+}
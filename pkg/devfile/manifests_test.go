@@ -0,0 +1,172 @@
+package devfile
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/devfile/library/pkg/devfile"
+	"github.com/devfile/library/pkg/devfile/parser"
+)
+
+const configMapManifest = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+`
+
+func TestLoadURI(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "kubernetes"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "kubernetes", "config.yaml"), []byte(configMapManifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/config.yaml" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(configMapManifest))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		uri     string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "relative path",
+			uri:  "kubernetes/config.yaml",
+			want: configMapManifest,
+		},
+		{
+			name: "absolute path",
+			uri:  filepath.Join(dir, "kubernetes", "config.yaml"),
+			want: configMapManifest,
+		},
+		{
+			name:    "missing file",
+			uri:     "kubernetes/missing.yaml",
+			wantErr: true,
+		},
+		{
+			name: "http URL",
+			uri:  server.URL + "/config.yaml",
+			want: configMapManifest,
+		},
+		{
+			name:    "http URL not found",
+			uri:     server.URL + "/missing.yaml",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := loadURI(dir, tt.uri)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadURI() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if string(got) != tt.want {
+				t.Errorf("loadURI() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetKubernetesManifestsFromURI(t *testing.T) {
+	tests := []struct {
+		name    string
+		devfile string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name: "inlined and uri components",
+			devfile: `schemaVersion: 2.2.0
+metadata:
+  name: my-component
+components:
+- name: inlined
+  kubernetes:
+    inlined: |
+      apiVersion: v1
+      kind: ConfigMap
+      metadata:
+        name: inlined
+- name: config
+  kubernetes:
+    uri: config.yaml
+`,
+			want: map[string]string{"config": configMapManifest},
+		},
+		{
+			name: "uri defined by a variable",
+			devfile: `schemaVersion: 2.2.0
+metadata:
+  name: my-component
+variables:
+  manifest: config.yaml
+components:
+- name: config
+  kubernetes:
+    uri: "{{manifest}}"
+`,
+			want: map[string]string{"config": configMapManifest},
+		},
+		{
+			name: "no uri component",
+			devfile: `schemaVersion: 2.2.0
+metadata:
+  name: my-component
+`,
+			want: map[string]string{},
+		},
+		{
+			name: "missing manifest",
+			devfile: `schemaVersion: 2.2.0
+metadata:
+  name: my-component
+components:
+- name: missing
+  kubernetes:
+    uri: missing.yaml
+`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(configMapManifest), 0644); err != nil {
+				t.Fatal(err)
+			}
+			devfilePath := filepath.Join(dir, "devfile.yaml")
+			if err := os.WriteFile(devfilePath, []byte(tt.devfile), 0644); err != nil {
+				t.Fatal(err)
+			}
+			devfileObj, _, err := devfile.ParseDevfileAndValidate(parser.ParserArgs{
+				Path: devfilePath,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := getKubernetesManifestsFromURI(devfileObj, devfilePath)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getKubernetesManifestsFromURI() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getKubernetesManifestsFromURI() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package devfile
+
+import (
+	"github.com/devfile/api/v2/pkg/validation/variables"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// logVariableWarning logs the references to undefined variables found in the devfile
+func logVariableWarning(varWarning variables.VariableWarning) {
+	logger := log.Log.WithName("variables")
+	for kind, warnings := range map[string]map[string][]string{
+		"command":        varWarning.Commands,
+		"component":      varWarning.Components,
+		"project":        varWarning.Projects,
+		"starterProject": varWarning.StarterProjects,
+	} {
+		for name, keys := range warnings {
+			logger.Info("undefined variables", kind, name, "variables", keys)
+		}
+	}
+}
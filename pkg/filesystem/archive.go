@@ -3,38 +3,73 @@ package filesystem
 import (
 	"os"
 	"path/filepath"
+	"strings"
 
 	gitignore "github.com/sabhiram/go-gitignore"
 )
 
-func Archive(path string, tarFile string, ignoreMatcher *gitignore.GitIgnore) (int64, error) {
-	err := os.Remove(tarFile)
+// Archive creates a tar file containing all the files of path not ignored by ignoreMatcher,
+// and returns the manifest of the archived files. See ListFiles for odoDir
+func Archive(path string, tarFile string, ignoreMatcher *gitignore.GitIgnore, odoDir string) (Manifest, error) {
+	files, err := ListFiles(path, ignoreMatcher, odoDir)
 	if err != nil {
-		if notExist := os.IsNotExist(err); !notExist {
-			return 0, err
-		}
+		return nil, err
+	}
+	return ArchiveFiles(path, tarFile, files)
+}
+
+// ArchiveFiles creates a tar file containing the files of path passed as relative paths,
+// and returns the manifest of the archived files
+func ArchiveFiles(path string, tarFile string, files []string) (Manifest, error) {
+	absFiles := make([]string, 0, len(files))
+	for _, file := range files {
+		absFiles = append(absFiles, filepath.Join(path, file))
+	}
+	err := writeTar(path, tarFile, absFiles)
+	if err != nil {
+		return nil, err
 	}
-	allFiles, err := getAllFiles(path, ignoreMatcher)
+	return NewManifest(path, files)
+}
+
+// ListFiles returns the paths, relative to path, of all the files of path not ignored by ignoreMatcher.
+// The .git directory and odoDir, the directory of the local files of ododev relative to path, are never listed
+func ListFiles(path string, ignoreMatcher *gitignore.GitIgnore, odoDir string) ([]string, error) {
+	allFiles, err := getAllFiles(path, ignoreMatcher, odoDir)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
-	tar, err := os.Create(tarFile)
+	result := make([]string, 0, len(allFiles))
+	for _, file := range allFiles {
+		rel, err := filepath.Rel(path, file)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, rel)
+	}
+	return result, nil
+}
+
+// writeTar writes the tar file atomically, so a reader never gets a partial content
+func writeTar(path string, tarFile string, files []string) error {
+	tmpFile := tarFile + ".tmp"
+	tar, err := os.Create(tmpFile)
 	if err != nil {
-		return 0, err
+		return err
 	}
-	err = MakeTar(path, path, tar, allFiles, nil)
+	err = MakeTar(path, path, tar, files, nil)
 	if err != nil {
-		return 0, err
+		tar.Close()
+		return err
 	}
-	info, err := os.Stat(tarFile)
+	err = tar.Close()
 	if err != nil {
-		return 0, err
+		return err
 	}
-	return info.ModTime().UnixNano(), nil
-
+	return os.Rename(tmpFile, tarFile)
 }
 
-func getAllFiles(rootPath string, ignoreMatcher *gitignore.GitIgnore) ([]string, error) {
+func getAllFiles(rootPath string, ignoreMatcher *gitignore.GitIgnore, odoDir string) ([]string, error) {
 	var result []string
 	err := filepath.Walk(rootPath,
 		func(path string, info os.FileInfo, err error) error {
@@ -56,7 +91,7 @@ func getAllFiles(rootPath string, ignoreMatcher *gitignore.GitIgnore) ([]string,
 			}
 
 			if info.IsDir() {
-				if rel == ".git" || rel == ".odo" {
+				if IsExcluded(rel, odoDir) {
 					return filepath.SkipDir
 				}
 				return nil
@@ -70,3 +105,32 @@ func getAllFiles(rootPath string, ignoreMatcher *gitignore.GitIgnore) ([]string,
 	}
 	return result, nil
 }
+
+// IsExcluded returns true if rel, relative to the directory of the sources, is in the .git directory
+// or in odoDir, the directory of the local files of ododev relative to the directory of the sources.
+// odoDir can be empty when it is not inside the directory of the sources
+func IsExcluded(rel string, odoDir string) bool {
+	rel = filepath.ToSlash(rel)
+	for _, dir := range []string{".git", filepath.ToSlash(odoDir)} {
+		if dir != "" && (rel == dir || strings.HasPrefix(rel, dir+"/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// RelativeDir returns the path of dir relative to path, or an empty string if dir is not inside path
+func RelativeDir(path string, dir string) (string, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(path, absDir)
+	if err != nil {
+		return "", err
+	}
+	if rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", nil
+	}
+	return rel, nil
+}
@@ -0,0 +1,163 @@
+package filesystem
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	gitignore "github.com/sabhiram/go-gitignore"
+)
+
+// readTar returns the files of an archive, indexed by name
+func readTar(t *testing.T, archive []byte) map[string]string {
+	t.Helper()
+	result := map[string]string{}
+	tr := tar.NewReader(bytes.NewReader(archive))
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return result
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result[hdr.Name] = string(content)
+	}
+}
+
+// writeFiles creates the files in dir, with their name as content
+func writeFiles(t *testing.T, dir string, files ...string) {
+	t.Helper()
+	for _, file := range files {
+		path := filepath.Join(dir, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(file), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestArchiveFiles(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []string
+		want  map[string]string
+	}{
+		{
+			name:  "files and files in sub-directories",
+			files: []string{"a", filepath.Join("dir", "b")},
+			want: map[string]string{
+				"a":     "a",
+				"dir/b": "dir/b",
+			},
+		},
+		{
+			name:  "file deleted after the change",
+			files: []string{"a", "deleted"},
+			want: map[string]string{
+				"a": "a",
+			},
+		},
+		{
+			name:  "no file",
+			files: nil,
+			want:  map[string]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, "a", filepath.Join("dir", "b"), "c")
+			tarFile := filepath.Join(t.TempDir(), "incremental.tar")
+			if _, err := ArchiveFiles(dir, tarFile, tt.files); err != nil {
+				t.Fatal(err)
+			}
+			archive, err := os.ReadFile(tarFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := readTar(t, archive); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ArchiveFiles() = %v, want %v", got, tt.want)
+			}
+			if _, err = os.Stat(tarFile + ".tmp"); !os.IsNotExist(err) {
+				t.Errorf("temporary file not removed")
+			}
+		})
+	}
+}
+
+func TestListFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, "a", filepath.Join("dir", "b"), filepath.Join("ignored", "c"), "d.log",
+		filepath.Join(".git", "HEAD"), filepath.Join(".ododev", "complete.tar"))
+	ignoreMatcher := gitignore.CompileIgnoreLines("ignored/", "*.log")
+	got, err := ListFiles(dir, ignoreMatcher, ".ododev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(got)
+	if want := []string{"a", filepath.Join("dir", "b")}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ListFiles() = %v, want %v", got, want)
+	}
+}
+
+func TestIsExcluded(t *testing.T) {
+	tests := []struct {
+		rel    string
+		odoDir string
+		want   bool
+	}{
+		{rel: ".git", odoDir: ".odo", want: true},
+		{rel: ".git/HEAD", odoDir: ".odo", want: true},
+		{rel: ".odo", odoDir: ".odo", want: true},
+		{rel: ".odo/complete.tar", odoDir: ".odo", want: true},
+		{rel: ".odo", odoDir: "", want: false},
+		{rel: "build/.ododev/diff.tar", odoDir: "build/.ododev", want: true},
+		{rel: "build/main.go", odoDir: "build/.ododev", want: false},
+		{rel: ".gitignore", odoDir: ".odo", want: false},
+		{rel: ".odoconfig", odoDir: ".odo", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.rel, func(t *testing.T) {
+			if got := IsExcluded(tt.rel, tt.odoDir); got != tt.want {
+				t.Errorf("IsExcluded(%q, %q) = %v, want %v", tt.rel, tt.odoDir, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRelativeDir(t *testing.T) {
+	path := t.TempDir()
+	tests := []struct {
+		name string
+		dir  string
+		want string
+	}{
+		{name: "inside", dir: filepath.Join(path, ".odo"), want: ".odo"},
+		{name: "nested", dir: filepath.Join(path, "build", ".odo"), want: filepath.Join("build", ".odo")},
+		{name: "same directory", dir: path, want: ""},
+		{name: "outside", dir: filepath.Join(filepath.Dir(path), ".odo"), want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RelativeDir(path, tt.dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("RelativeDir() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
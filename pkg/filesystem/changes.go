@@ -0,0 +1,92 @@
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Changes accumulates the files modified and deleted since the complete archive has been created
+type Changes struct {
+	// path is the directory of the sources, the files being relative to it
+	path     string
+	modified map[string]struct{}
+	deleted  map[string]struct{}
+}
+
+func NewChanges(path string) *Changes {
+	return &Changes{
+		path:     path,
+		modified: map[string]struct{}{},
+		deleted:  map[string]struct{}{},
+	}
+}
+
+// Add records new deleted and modified files. A file deleted then created again
+// is considered as modified, and a file modified then deleted is considered as deleted.
+// A file both deleted and modified in the same call is considered as modified if it exists, as deleted otherwise,
+// as the order of the events is not known
+func (o *Changes) Add(deleted []string, modified []string) {
+	both := map[string]bool{}
+	for _, file := range modified {
+		both[file] = false
+	}
+	for _, file := range deleted {
+		if _, ok := both[file]; ok {
+			both[file] = true
+		}
+	}
+	for _, file := range deleted {
+		if both[file] && o.exists(file) {
+			continue
+		}
+		delete(o.modified, file)
+		o.deleted[file] = struct{}{}
+	}
+	for _, file := range modified {
+		if both[file] && !o.exists(file) {
+			continue
+		}
+		delete(o.deleted, file)
+		o.modified[file] = struct{}{}
+	}
+}
+
+func (o *Changes) exists(file string) bool {
+	_, err := os.Lstat(filepath.Join(o.path, file))
+	return err == nil
+}
+
+// Modified returns the sorted list of modified files
+func (o *Changes) Modified() []string {
+	return sortedKeys(o.modified)
+}
+
+// Deleted returns the sorted list of deleted files
+func (o *Changes) Deleted() []string {
+	return sortedKeys(o.deleted)
+}
+
+// Apply returns the list of files resulting of the changes applied to files
+func (o *Changes) Apply(files []string) []string {
+	result := make(map[string]struct{}, len(files)+len(o.modified))
+	for _, file := range files {
+		result[file] = struct{}{}
+	}
+	for file := range o.modified {
+		result[file] = struct{}{}
+	}
+	for file := range o.deleted {
+		delete(result, file)
+	}
+	return sortedKeys(result)
+}
+
+func sortedKeys(m map[string]struct{}) []string {
+	result := make([]string, 0, len(m))
+	for k := range m {
+		result = append(result, k)
+	}
+	sort.Strings(result)
+	return result
+}
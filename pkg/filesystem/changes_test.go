@@ -0,0 +1,146 @@
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestChanges(t *testing.T) {
+	// event is a notification of files deleted and modified
+	type event struct {
+		deleted  []string
+		modified []string
+	}
+	tests := []struct {
+		name   string
+		events []event
+		// existing are the files existing in the sources directory after the events
+		existing     []string
+		files        []string
+		wantModified []string
+		wantDeleted  []string
+		wantApplied  []string
+	}{
+		{
+			name:         "no change",
+			files:        []string{"a", "b"},
+			wantModified: []string{},
+			wantDeleted:  []string{},
+			wantApplied:  []string{"a", "b"},
+		},
+		{
+			name: "modified and deleted files",
+			events: []event{
+				{modified: []string{"c", "b"}},
+				{deleted: []string{"a"}},
+			},
+			files:        []string{"a", "b"},
+			wantModified: []string{"b", "c"},
+			wantDeleted:  []string{"a"},
+			wantApplied:  []string{"b", "c"},
+		},
+		{
+			name: "file modified several times",
+			events: []event{
+				{modified: []string{"a"}},
+				{modified: []string{"a"}},
+			},
+			files:        []string{"a"},
+			wantModified: []string{"a"},
+			wantDeleted:  []string{},
+			wantApplied:  []string{"a"},
+		},
+		{
+			name: "file deleted then created again",
+			events: []event{
+				{deleted: []string{"a"}},
+				{modified: []string{"a"}},
+			},
+			files:        []string{"a"},
+			wantModified: []string{"a"},
+			wantDeleted:  []string{},
+			wantApplied:  []string{"a"},
+		},
+		{
+			name: "file modified then deleted",
+			events: []event{
+				{modified: []string{"a"}},
+				{deleted: []string{"a"}},
+			},
+			files:        []string{"a", "b"},
+			wantModified: []string{},
+			wantDeleted:  []string{"a"},
+			wantApplied:  []string{"b"},
+		},
+		{
+			name: "file created then deleted",
+			events: []event{
+				{modified: []string{"c"}},
+				{deleted: []string{"c"}},
+			},
+			files:        []string{"a"},
+			wantModified: []string{},
+			wantDeleted:  []string{"c"},
+			wantApplied:  []string{"a"},
+		},
+		{
+			name: "existing file deleted and modified in the same event",
+			events: []event{
+				{deleted: []string{"a"}, modified: []string{"a"}},
+			},
+			existing:     []string{"a"},
+			files:        []string{"a"},
+			wantModified: []string{"a"},
+			wantDeleted:  []string{},
+			wantApplied:  []string{"a"},
+		},
+		{
+			name: "temporary file created and deleted in the same event",
+			events: []event{
+				{deleted: []string{"4913"}, modified: []string{"4913"}},
+			},
+			existing:     []string{"a"},
+			files:        []string{"a"},
+			wantModified: []string{},
+			wantDeleted:  []string{"4913"},
+			wantApplied:  []string{"a"},
+		},
+		{
+			name: "modified file deleted in the same event",
+			events: []event{
+				{modified: []string{"a"}},
+				{deleted: []string{"a"}, modified: []string{"a"}},
+			},
+			files:        []string{"a", "b"},
+			existing:     []string{"b"},
+			wantModified: []string{},
+			wantDeleted:  []string{"a"},
+			wantApplied:  []string{"b"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, file := range tt.existing {
+				if err := os.WriteFile(filepath.Join(dir, file), nil, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			changes := NewChanges(dir)
+			for _, e := range tt.events {
+				changes.Add(e.deleted, e.modified)
+			}
+			if got := changes.Modified(); !reflect.DeepEqual(got, tt.wantModified) {
+				t.Errorf("Modified() = %v, want %v", got, tt.wantModified)
+			}
+			if got := changes.Deleted(); !reflect.DeepEqual(got, tt.wantDeleted) {
+				t.Errorf("Deleted() = %v, want %v", got, tt.wantDeleted)
+			}
+			if got := changes.Apply(tt.files); !reflect.DeepEqual(got, tt.wantApplied) {
+				t.Errorf("Apply() = %v, want %v", got, tt.wantApplied)
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package filesystem
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// digestPrefix is the prefix of the digests of the manifests
+const digestPrefix = "sha256:"
+
+// ManifestEntry describes the content of a file
+type ManifestEntry struct {
+	// Path is the path of the file, relative to the sources directory, with slashes
+	Path string      `json:"path"`
+	Mode os.FileMode `json:"mode"`
+	// SHA256 is the hex encoded SHA-256 of the content of the file, or of the target of a symbolic link
+	SHA256 string `json:"sha256"`
+}
+
+// Manifest describes the content of a set of files, sorted by path
+type Manifest []ManifestEntry
+
+// NewManifest returns the manifest of the files of path passed as relative paths.
+// The files not existing anymore are skipped, as they are by MakeTar
+func NewManifest(path string, files []string) (Manifest, error) {
+	result := make(Manifest, 0, len(files))
+	for _, file := range files {
+		absFile := filepath.Join(path, file)
+		info, err := os.Lstat(absFile)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		sum, err := getChecksum(absFile, info)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, ManifestEntry{
+			Path:   filepath.ToSlash(file),
+			Mode:   info.Mode(),
+			SHA256: sum,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Path < result[j].Path
+	})
+	return result, nil
+}
+
+func getChecksum(file string, info os.FileInfo) (string, error) {
+	h := sha256.New()
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		target, err := os.Readlink(file)
+		if err != nil {
+			return "", err
+		}
+		h.Write([]byte(target))
+	case info.Mode().IsRegular():
+		f, err := os.Open(file)
+		if err != nil {
+			return "", err
+		}
+		defer f.Close()
+		if _, err = io.Copy(h, f); err != nil {
+			return "", err
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Digest returns the digest of the manifest, identical for identical contents
+func (m Manifest) Digest() string {
+	h := sha256.New()
+	for _, entry := range m {
+		fmt.Fprintf(h, "%s %o %s\n", entry.SHA256, uint32(entry.Mode), entry.Path)
+	}
+	return digestPrefix + hex.EncodeToString(h.Sum(nil))
+}
+
+// Diff returns the files added or modified in current, and the files deleted from previous.
+// The returned paths are relative to the sources directory, with slashes
+func Diff(previous Manifest, current Manifest) (modified []string, deleted []string) {
+	previousEntries := make(map[string]ManifestEntry, len(previous))
+	for _, entry := range previous {
+		previousEntries[entry.Path] = entry
+	}
+	for _, entry := range current {
+		if prev, ok := previousEntries[entry.Path]; !ok || prev != entry {
+			modified = append(modified, entry.Path)
+		}
+		delete(previousEntries, entry.Path)
+	}
+	for path := range previousEntries {
+		deleted = append(deleted, path)
+	}
+	sort.Strings(deleted)
+	return modified, deleted
+}
+
+// ReadManifest reads a manifest written by WriteManifest.
+// A nil manifest is returned if the file does not exist
+func ReadManifest(file string) (Manifest, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var result Manifest
+	err = json.Unmarshal(content, &result)
+	return result, err
+}
+
+// WriteManifest writes a manifest atomically, so a reader never gets a partial content
+func WriteManifest(file string, manifest Manifest) error {
+	content, err := json.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(file), 0755)
+	if err != nil {
+		return err
+	}
+	tmpFile := file + ".tmp"
+	err = os.WriteFile(tmpFile, content, 0644)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmpFile, file)
+}
+
+// EncodeManifest returns the compressed serialization of the manifest
+func EncodeManifest(manifest Manifest) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(w).Encode(manifest); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// DecodeManifest returns the manifest serialized with EncodeManifest
+func DecodeManifest(data []byte) (Manifest, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	var result Manifest
+	err = json.NewDecoder(r).Decode(&result)
+	return result, err
+}
@@ -0,0 +1,206 @@
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func entry(path string, sha256 string) ManifestEntry {
+	return ManifestEntry{
+		Path:   path,
+		Mode:   0644,
+		SHA256: sha256,
+	}
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name         string
+		previous     Manifest
+		current      Manifest
+		wantModified []string
+		wantDeleted  []string
+	}{
+		{
+			name:     "identical manifests",
+			previous: Manifest{entry("a", "1"), entry("b", "2")},
+			current:  Manifest{entry("a", "1"), entry("b", "2")},
+		},
+		{
+			name:         "added file",
+			previous:     Manifest{entry("a", "1")},
+			current:      Manifest{entry("a", "1"), entry("dir/b", "2")},
+			wantModified: []string{"dir/b"},
+		},
+		{
+			name:         "modified content",
+			previous:     Manifest{entry("a", "1"), entry("b", "2")},
+			current:      Manifest{entry("a", "1"), entry("b", "3")},
+			wantModified: []string{"b"},
+		},
+		{
+			name:     "modified mode",
+			previous: Manifest{entry("a", "1")},
+			current: Manifest{{
+				Path:   "a",
+				Mode:   0755,
+				SHA256: "1",
+			}},
+			wantModified: []string{"a"},
+		},
+		{
+			name:        "deleted files",
+			previous:    Manifest{entry("a", "1"), entry("c", "3"), entry("b", "2")},
+			current:     Manifest{entry("b", "2")},
+			wantDeleted: []string{"a", "c"},
+		},
+		{
+			name:         "added, modified and deleted files",
+			previous:     Manifest{entry("a", "1"), entry("b", "2")},
+			current:      Manifest{entry("b", "3"), entry("c", "4")},
+			wantModified: []string{"b", "c"},
+			wantDeleted:  []string{"a"},
+		},
+		{
+			name:         "no previous manifest",
+			current:      Manifest{entry("a", "1")},
+			wantModified: []string{"a"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			modified, deleted := Diff(tt.previous, tt.current)
+			if !reflect.DeepEqual(modified, tt.wantModified) {
+				t.Errorf("Diff() modified = %v, want %v", modified, tt.wantModified)
+			}
+			if !reflect.DeepEqual(deleted, tt.wantDeleted) {
+				t.Errorf("Diff() deleted = %v, want %v", deleted, tt.wantDeleted)
+			}
+		})
+	}
+}
+
+func TestDigest(t *testing.T) {
+	base := Manifest{entry("a", "1"), entry("b", "2")}
+	tests := []struct {
+		name     string
+		manifest Manifest
+		wantSame bool
+	}{
+		{
+			name:     "identical manifest",
+			manifest: Manifest{entry("a", "1"), entry("b", "2")},
+			wantSame: true,
+		},
+		{
+			name:     "modified content",
+			manifest: Manifest{entry("a", "1"), entry("b", "3")},
+		},
+		{
+			name: "modified mode",
+			manifest: Manifest{entry("a", "1"), {
+				Path:   "b",
+				Mode:   0755,
+				SHA256: "2",
+			}},
+		},
+		{
+			name:     "renamed file",
+			manifest: Manifest{entry("a", "1"), entry("c", "2")},
+		},
+		{
+			name:     "added file",
+			manifest: Manifest{entry("a", "1"), entry("b", "2"), entry("c", "3")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			same := tt.manifest.Digest() == base.Digest()
+			if same != tt.wantSame {
+				t.Errorf("Digest() same = %v, want %v", same, tt.wantSame)
+			}
+		})
+	}
+}
+
+func TestNewManifestOrder(t *testing.T) {
+	dir := t.TempDir()
+	for _, file := range []string{"b", "a", "dir/c"} {
+		path := filepath.Join(dir, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(file), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	first, err := NewManifest(dir, []string{"b", "a", filepath.Join("dir", "c")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := NewManifest(dir, []string{filepath.Join("dir", "c"), "a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("NewManifest() depends on the order of the files: %v != %v", first, second)
+	}
+	if first.Digest() != second.Digest() {
+		t.Errorf("Digest() depends on the order of the files")
+	}
+	var paths []string
+	for _, e := range first {
+		paths = append(paths, e.Path)
+	}
+	if want := []string{"a", "b", "dir/c"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("NewManifest() paths = %v, want %v", paths, want)
+	}
+}
+
+func TestEncodeManifest(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest Manifest
+	}{
+		{
+			name:     "files",
+			manifest: Manifest{entry("a", "1"), entry("dir/b", "2")},
+		},
+		{
+			name:     "no file",
+			manifest: Manifest{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := EncodeManifest(tt.manifest)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := DecodeManifest(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.manifest) {
+				t.Errorf("DecodeManifest() = %v, want %v", got, tt.manifest)
+			}
+		})
+	}
+}
+
+func TestNewManifestMissingFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a"), []byte("a"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	manifest, err := NewManifest(dir, []string{"a", "deleted"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(manifest) != 1 || manifest[0].Path != "a" {
+		t.Errorf("NewManifest() = %v, want only file a", manifest)
+	}
+}
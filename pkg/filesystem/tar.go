@@ -2,10 +2,12 @@ package filesystem
 
 import (
 	"archive/tar"
+	"bytes"
 	"fmt"
 	"io"
 	"os"
 	"os/user"
+	"path"
 	"path/filepath"
 	"strings"
 
@@ -201,3 +203,43 @@ func getAbsPath(path string) (string, error) {
 	}
 	return path, nil
 }
+
+// FilterTar returns an archive containing only the entries of archive for the files,
+// passed as paths relative to the sources directory with slashes.
+// An error is returned if a file is not found in archive
+func FilterTar(archive []byte, files []string) ([]byte, error) {
+	wanted := make(map[string]bool, len(files))
+	for _, file := range files {
+		wanted[file] = true
+	}
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	tr := tar.NewReader(bytes.NewReader(archive))
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		name := path.Clean(hdr.Name)
+		if !wanted[name] {
+			continue
+		}
+		delete(wanted, name)
+		if err = tw.WriteHeader(hdr); err != nil {
+			return nil, err
+		}
+		if _, err = io.Copy(tw, tr); err != nil {
+			return nil, err
+		}
+	}
+	for file := range wanted {
+		return nil, fmt.Errorf("file %q not found in archive", file)
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,81 @@
+package filesystem
+
+import (
+	"archive/tar"
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+// newTar returns an archive containing the files, indexed by name
+func newTar(t *testing.T, files map[string]string, order []string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, name := range order {
+		content := files[name]
+		err := tw.WriteHeader(&tar.Header{
+			Name: name,
+			Mode: 0644,
+			Size: int64(len(content)),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestFilterTar(t *testing.T) {
+	archive := newTar(t, map[string]string{
+		"a":     "content a",
+		"dir/b": "content b",
+		"c":     "content c",
+	}, []string{"a", "dir/b", "c"})
+
+	tests := []struct {
+		name    string
+		files   []string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name:  "some files",
+			files: []string{"dir/b", "a"},
+			want: map[string]string{
+				"a":     "content a",
+				"dir/b": "content b",
+			},
+		},
+		{
+			name:  "no file",
+			files: nil,
+			want:  map[string]string{},
+		},
+		{
+			name:    "file not in archive",
+			files:   []string{"a", "d"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filtered, err := FilterTar(archive, tt.files)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FilterTar() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := readTar(t, filtered); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FilterTar() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
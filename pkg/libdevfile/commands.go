@@ -0,0 +1,117 @@
+package libdevfile
+
+import (
+	"strings"
+
+	"github.com/devfile/api/v2/pkg/apis/workspaces/v1alpha2"
+	"github.com/devfile/library/pkg/devfile/parser"
+	"github.com/devfile/library/pkg/devfile/parser/data/v2/common"
+)
+
+// CommandTree is a devfile command, with the commands referenced by a composite command expanded
+type CommandTree struct {
+	Command v1alpha2.Command
+	// SubCommands are the expanded commands of a composite command, nil for other commands
+	SubCommands []CommandTree
+	// Parallel is true when the sub-commands of a composite command are executed in parallel
+	Parallel bool
+}
+
+// IsComposite returns true if the command is a composite command
+func (t CommandTree) IsComposite() bool {
+	return t.Command.Composite != nil
+}
+
+// Leaves returns the non-composite commands of the tree, in the order they are defined
+func (t CommandTree) Leaves() []v1alpha2.Command {
+	if !t.IsComposite() {
+		return []v1alpha2.Command{t.Command}
+	}
+	var result []v1alpha2.Command
+	for _, sub := range t.SubCommands {
+		result = append(result, sub.Leaves()...)
+	}
+	return result
+}
+
+// ExpandCommand expands recursively the commands referenced by a composite command.
+// An error is returned if a referenced command does not exist, or if a composite command references itself
+func ExpandCommand(devfileObj parser.DevfileObj, cmd v1alpha2.Command) (CommandTree, error) {
+	commandsByID, err := getCommandsByID(devfileObj)
+	if err != nil {
+		return CommandTree{}, err
+	}
+	return expandCommand(commandsByID, cmd, nil)
+}
+
+// getCommandsByID returns the commands of the devfile indexed by their lowercase id
+func getCommandsByID(devfileObj parser.DevfileObj) (map[string]v1alpha2.Command, error) {
+	commands, err := devfileObj.Data.GetCommands(common.DevfileOptions{})
+	if err != nil {
+		return nil, err
+	}
+	commandsByID := make(map[string]v1alpha2.Command, len(commands))
+	for _, command := range commands {
+		commandsByID[strings.ToLower(command.Id)] = command
+	}
+	return commandsByID, nil
+}
+
+// expandCommand expands cmd, path contains the ids of the composite commands being expanded
+func expandCommand(commandsByID map[string]v1alpha2.Command, cmd v1alpha2.Command, path []string) (CommandTree, error) {
+	if cmd.Composite == nil {
+		return CommandTree{
+			Command: cmd,
+		}, nil
+	}
+	for i, id := range path {
+		if strings.EqualFold(id, cmd.Id) {
+			cycle := append(append([]string{}, path[i:]...), cmd.Id)
+			return CommandTree{}, NewCommandCycleError(cycle)
+		}
+	}
+	path = append(path, cmd.Id)
+
+	tree := CommandTree{
+		Command:     cmd,
+		SubCommands: make([]CommandTree, 0, len(cmd.Composite.Commands)),
+		Parallel:    cmd.Composite.Parallel != nil && *cmd.Composite.Parallel,
+	}
+	for _, id := range cmd.Composite.Commands {
+		sub, ok := commandsByID[strings.ToLower(id)]
+		if !ok {
+			return CommandTree{}, NewCommandNotExistError(id)
+		}
+		subTree, err := expandCommand(commandsByID, sub, path)
+		if err != nil {
+			return CommandTree{}, err
+		}
+		tree.SubCommands = append(tree.SubCommands, subTree)
+	}
+	return tree, nil
+}
+
+// GetEventCommands returns the expanded commands referenced by a devfile event, as preStart or postStart,
+// in the order they are defined
+func GetEventCommands(devfileObj parser.DevfileObj, commandIDs []string) ([]CommandTree, error) {
+	if len(commandIDs) == 0 {
+		return nil, nil
+	}
+	commandsByID, err := getCommandsByID(devfileObj)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]CommandTree, 0, len(commandIDs))
+	for _, id := range commandIDs {
+		cmd, ok := commandsByID[strings.ToLower(id)]
+		if !ok {
+			return nil, NewCommandNotExistError(id)
+		}
+		tree, err := expandCommand(commandsByID, cmd, nil)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, tree)
+	}
+	return result, nil
+}
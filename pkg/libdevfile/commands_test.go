@@ -0,0 +1,226 @@
+package libdevfile
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/devfile/api/v2/pkg/apis/workspaces/v1alpha2"
+	"github.com/devfile/library/pkg/devfile/parser"
+	"github.com/devfile/library/pkg/devfile/parser/data"
+	"k8s.io/utils/pointer"
+)
+
+// newDevfileObj returns a devfile containing the components and commands, without validating it
+func newDevfileObj(t *testing.T, components []v1alpha2.Component, commands []v1alpha2.Command) parser.DevfileObj {
+	t.Helper()
+	devfileData, err := data.NewDevfileData(string(data.APISchemaVersion220))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = devfileData.AddComponents(components); err != nil {
+		t.Fatal(err)
+	}
+	if err = devfileData.AddCommands(commands); err != nil {
+		t.Fatal(err)
+	}
+	return parser.DevfileObj{
+		Data: devfileData,
+	}
+}
+
+func execCommand(id string) v1alpha2.Command {
+	return v1alpha2.Command{
+		Id: id,
+		CommandUnion: v1alpha2.CommandUnion{
+			Exec: &v1alpha2.ExecCommand{
+				CommandLine: "echo " + id,
+				Component:   "runtime",
+			},
+		},
+	}
+}
+
+func compositeCommand(id string, parallel bool, commands ...string) v1alpha2.Command {
+	return v1alpha2.Command{
+		Id: id,
+		CommandUnion: v1alpha2.CommandUnion{
+			Composite: &v1alpha2.CompositeCommand{
+				Commands: commands,
+				Parallel: pointer.Bool(parallel),
+			},
+		},
+	}
+}
+
+func TestExpandCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		commands []v1alpha2.Command
+		// command is the id of the expanded command
+		command    string
+		wantLeaves []string
+		wantErr    error
+	}{
+		{
+			name:       "exec command",
+			commands:   []v1alpha2.Command{execCommand("build")},
+			command:    "build",
+			wantLeaves: []string{"build"},
+		},
+		{
+			name: "nested composite commands",
+			commands: []v1alpha2.Command{
+				execCommand("compile"),
+				execCommand("zip"),
+				execCommand("upload"),
+				compositeCommand("package", true, "zip", "upload"),
+				compositeCommand("build", false, "compile", "package"),
+			},
+			command:    "build",
+			wantLeaves: []string{"compile", "zip", "upload"},
+		},
+		{
+			name: "reference with a different case",
+			commands: []v1alpha2.Command{
+				execCommand("compile"),
+				compositeCommand("build", false, "Compile"),
+			},
+			command:    "build",
+			wantLeaves: []string{"compile"},
+		},
+		{
+			name: "self reference",
+			commands: []v1alpha2.Command{
+				execCommand("compile"),
+				compositeCommand("build", false, "compile", "build"),
+			},
+			command: "build",
+			wantErr: NewCommandCycleError([]string{"build", "build"}),
+		},
+		{
+			name: "cycle through another composite command",
+			commands: []v1alpha2.Command{
+				compositeCommand("a", false, "b"),
+				compositeCommand("b", false, "a"),
+			},
+			command: "a",
+			wantErr: NewCommandCycleError([]string{"a", "b", "a"}),
+		},
+		{
+			name: "missing command",
+			commands: []v1alpha2.Command{
+				execCommand("compile"),
+				compositeCommand("build", false, "compile", "test"),
+			},
+			command: "build",
+			wantErr: NewCommandNotExistError("test"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			devfileObj := newDevfileObj(t, nil, tt.commands)
+			var cmd v1alpha2.Command
+			for _, c := range tt.commands {
+				if c.Id == tt.command {
+					cmd = c
+				}
+			}
+
+			tree, err := ExpandCommand(devfileObj, cmd)
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Fatalf("ExpandCommand() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			var leaves []string
+			for _, leaf := range tree.Leaves() {
+				leaves = append(leaves, leaf.Id)
+			}
+			if !reflect.DeepEqual(leaves, tt.wantLeaves) {
+				t.Errorf("ExpandCommand() leaves = %v, want %v", leaves, tt.wantLeaves)
+			}
+		})
+	}
+}
+
+func TestExpandCommandParallel(t *testing.T) {
+	build := compositeCommand("build", false, "package")
+	devfileObj := newDevfileObj(t, nil, []v1alpha2.Command{
+		execCommand("zip"),
+		execCommand("upload"),
+		compositeCommand("package", true, "zip", "upload"),
+		build,
+	})
+
+	tree, err := ExpandCommand(devfileObj, build)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !tree.IsComposite() || tree.Parallel {
+		t.Errorf("build: composite = %v, parallel = %v, want composite sequential command", tree.IsComposite(), tree.Parallel)
+	}
+	if len(tree.SubCommands) != 1 {
+		t.Fatalf("build: %d sub-commands, want 1", len(tree.SubCommands))
+	}
+	pkg := tree.SubCommands[0]
+	if !pkg.IsComposite() || !pkg.Parallel || len(pkg.SubCommands) != 2 {
+		t.Errorf("package: composite = %v, parallel = %v, %d sub-commands, want composite parallel command with 2 sub-commands",
+			pkg.IsComposite(), pkg.Parallel, len(pkg.SubCommands))
+	}
+}
+
+func TestGetEventCommands(t *testing.T) {
+	commands := []v1alpha2.Command{
+		execCommand("init"),
+		execCommand("install"),
+		execCommand("migrate"),
+		compositeCommand("prepare", false, "install", "migrate"),
+	}
+	tests := []struct {
+		name       string
+		commandIDs []string
+		// wantLeaves are the ids of the leaves of each expanded command
+		wantLeaves [][]string
+		wantErr    error
+	}{
+		{
+			name: "no command",
+		},
+		{
+			name:       "commands in the order of the event",
+			commandIDs: []string{"prepare", "init"},
+			wantLeaves: [][]string{{"install", "migrate"}, {"init"}},
+		},
+		{
+			name:       "reference with a different case",
+			commandIDs: []string{"Init"},
+			wantLeaves: [][]string{{"init"}},
+		},
+		{
+			name:       "missing command",
+			commandIDs: []string{"init", "seed"},
+			wantErr:    NewCommandNotExistError("seed"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			devfileObj := newDevfileObj(t, nil, commands)
+			trees, err := GetEventCommands(devfileObj, tt.commandIDs)
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Fatalf("GetEventCommands() error = %v, want %v", err, tt.wantErr)
+			}
+			var leaves [][]string
+			for _, tree := range trees {
+				var ids []string
+				for _, leaf := range tree.Leaves() {
+					ids = append(ids, leaf.Id)
+				}
+				leaves = append(leaves, ids)
+			}
+			if !reflect.DeepEqual(leaves, tt.wantLeaves) {
+				t.Errorf("GetEventCommands() leaves = %v, want %v", leaves, tt.wantLeaves)
+			}
+		})
+	}
+}
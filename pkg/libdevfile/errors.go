@@ -2,6 +2,7 @@ package libdevfile
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/devfile/api/v2/pkg/apis/workspaces/v1alpha2"
 )
@@ -92,3 +93,33 @@ func NewComponentTypeNotFoundError(componentType v1alpha2.ComponentType) Compone
 func (e ComponentTypeNotFoundError) Error() string {
 	return fmt.Sprintf("no component with type %q found in Devfile", e.componentType)
 }
+
+// CommandNotExistError is returned when a command referenced by a composite command does not exist
+type CommandNotExistError struct {
+	id string
+}
+
+func NewCommandNotExistError(id string) CommandNotExistError {
+	return CommandNotExistError{
+		id: id,
+	}
+}
+
+func (e CommandNotExistError) Error() string {
+	return fmt.Sprintf("command %q does not exist", e.id)
+}
+
+// CommandCycleError is returned when a composite command references itself, directly or through other composite commands
+type CommandCycleError struct {
+	cycle []string
+}
+
+func NewCommandCycleError(cycle []string) CommandCycleError {
+	return CommandCycleError{
+		cycle: cycle,
+	}
+}
+
+func (e CommandCycleError) Error() string {
+	return fmt.Sprintf("cycle detected in composite commands: %s", strings.Join(e.cycle, " -> "))
+}
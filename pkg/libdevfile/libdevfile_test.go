@@ -0,0 +1,133 @@
+package libdevfile
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/devfile/library/pkg/devfile"
+	"github.com/devfile/library/pkg/devfile/parser"
+)
+
+// parseDevfile returns the devfile parsed from its content
+func parseDevfile(t *testing.T, content string) parser.DevfileObj {
+	t.Helper()
+	devfileObj, _, err := devfile.ParseDevfileAndValidate(parser.ParserArgs{
+		Data: []byte(content),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return devfileObj
+}
+
+func TestGetSyncTargets(t *testing.T) {
+	tests := []struct {
+		name    string
+		devfile string
+		want    []SyncTarget
+	}{
+		{
+			name: "default source mapping",
+			devfile: `schemaVersion: 2.2.0
+metadata:
+  name: my-component
+components:
+- name: runtime
+  container:
+    image: image
+`,
+			want: []SyncTarget{{ContainerName: "runtime", Path: "/projects"}},
+		},
+		{
+			name: "custom source mapping",
+			devfile: `schemaVersion: 2.2.0
+metadata:
+  name: my-component
+components:
+- name: runtime
+  container:
+    image: image
+    sourceMapping: /src
+`,
+			want: []SyncTarget{{ContainerName: "runtime", Path: "/src"}},
+		},
+		{
+			name: "container not mounting sources",
+			devfile: `schemaVersion: 2.2.0
+metadata:
+  name: my-component
+components:
+- name: runtime
+  container:
+    image: image
+- name: db
+  container:
+    image: db
+    mountSources: false
+`,
+			want: []SyncTarget{{ContainerName: "runtime", Path: "/projects"}},
+		},
+		{
+			name: "several containers mounting sources",
+			devfile: `schemaVersion: 2.2.0
+metadata:
+  name: my-component
+components:
+- name: runtime
+  container:
+    image: image
+- name: tools
+  container:
+    image: tools
+    mountSources: true
+    sourceMapping: /tools
+`,
+			want: []SyncTarget{
+				{ContainerName: "runtime", Path: "/projects"},
+				{ContainerName: "tools", Path: "/tools"},
+			},
+		},
+		{
+			name: "project with clone path",
+			devfile: `schemaVersion: 2.2.0
+metadata:
+  name: my-component
+projects:
+- name: my-project
+  clonePath: src/app
+  git:
+    remotes:
+      origin: https://github.com/example/app.git
+components:
+- name: runtime
+  container:
+    image: image
+`,
+			want: []SyncTarget{{ContainerName: "runtime", Path: "/projects/src/app"}},
+		},
+		{
+			name: "no container mounting sources",
+			devfile: `schemaVersion: 2.2.0
+metadata:
+  name: my-component
+components:
+- name: db
+  container:
+    image: db
+    mountSources: false
+`,
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetSyncTargets(parseDevfile(t, tt.devfile))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetSyncTargets() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,237 @@
+package libdevfile
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+
+	"github.com/devfile/api/v2/pkg/apis/workspaces/v1alpha2"
+	"github.com/devfile/library/pkg/devfile/parser"
+	"github.com/devfile/library/pkg/devfile/parser/data/v2/common"
+)
+
+const (
+	// firstLocalPort is the first local port tried to forward the endpoints
+	firstLocalPort = 40001
+	// localPortAttribute is the attribute of an endpoint defining the local port to which it is forwarded
+	localPortAttribute = "localPort"
+
+	// debugEndpointName is the name of the endpoint exposing the debug port
+	debugEndpointName = "debug"
+	// debugPortEnv is the env var defining the debug port, when no debug endpoint is defined
+	debugPortEnv = "DEBUG_PORT"
+	// debugLocalPort is the first local port tried to forward the debug port
+	debugLocalPort = 5858
+)
+
+// PortPair is a port of a container forwarded to a local port
+type PortPair struct {
+	ContainerName string
+	// EndpointName is the name of the endpoint, or "debug" for the debug port
+	EndpointName  string
+	LocalPort     int
+	ContainerPort int
+}
+
+// String returns the pair as local:remote
+func (o PortPair) String() string {
+	return fmt.Sprintf("%d:%d", o.LocalPort, o.ContainerPort)
+}
+
+// LocalPorts are the local ports requested for the endpoints, indexed by endpoint name
+type LocalPorts struct {
+	// Pinned are the ports requested by the user, used even if they are not free
+	Pinned map[string]int
+	// Previous are the ports assigned during a previous session, used again when they are free
+	Previous map[string]int
+}
+
+// GetPortPairs returns the pairs of ports to forward the exposed endpoints of the containers, and the debug port when debug is true.
+// The endpoints are sorted by container name, target port and endpoint name, so the same local ports are assigned on every run.
+// A local port is, in order of precedence, the one pinned by the user, the one of the localPort attribute of the endpoint,
+// the one assigned during a previous session if free, or the first free port starting at 40001 (5858 for the debug port).
+// The ports pinned for endpoints not forwarded are ignored, see CheckPinnedPorts
+func GetPortPairs(devFileObj parser.DevfileObj, localPorts LocalPorts, debug bool) ([]PortPair, error) {
+	pairs, requested, err := getEndpointPairs(devFileObj, debug)
+	if err != nil {
+		return nil, err
+	}
+
+	used := map[int]bool{}
+	// ports requested by the user first, so they are not assigned to other endpoints
+	for i := range pairs {
+		name := pairs[i].EndpointName
+		port, ok := localPorts.Pinned[name]
+		if !ok {
+			port, ok = requested[name]
+		}
+		if !ok {
+			continue
+		}
+		if used[port] {
+			return nil, fmt.Errorf("local port %d requested for endpoint %q is already assigned to another endpoint", port, name)
+		}
+		pairs[i].LocalPort = port
+		used[port] = true
+	}
+	for i := range pairs {
+		if pairs[i].LocalPort != 0 {
+			continue
+		}
+		port, ok := localPorts.Previous[pairs[i].EndpointName]
+		if !ok || used[port] || !isPortFree(port) {
+			continue
+		}
+		pairs[i].LocalPort = port
+		used[port] = true
+	}
+	for i := range pairs {
+		if pairs[i].LocalPort != 0 {
+			continue
+		}
+		port := firstLocalPort
+		if debug && pairs[i].EndpointName == debugEndpointName {
+			port = debugLocalPort
+		}
+		for used[port] || !isPortFree(port) {
+			port++
+		}
+		pairs[i].LocalPort = port
+		used[port] = true
+	}
+	return pairs, nil
+}
+
+// CheckPinnedPorts returns an error if local ports are pinned for endpoints which are not forwarded
+func CheckPinnedPorts(devFileObj parser.DevfileObj, pinned map[string]int, debug bool) error {
+	pairs, _, err := getEndpointPairs(devFileObj, debug)
+	if err != nil {
+		return err
+	}
+	var unknown []string
+	for name := range pinned {
+		if !hasEndpoint(pairs, name) {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	sort.Strings(unknown)
+	available := make([]string, 0, len(pairs))
+	for _, pair := range pairs {
+		available = append(available, pair.EndpointName)
+	}
+	return fmt.Errorf("local ports pinned for unknown endpoints %v, forwarded endpoints are %v", unknown, available)
+}
+
+// getEndpointPairs returns the pairs of the endpoints to forward, without local ports, sorted by container name,
+// target port and endpoint name, and the local ports defined by the localPort attributes, indexed by endpoint name
+func getEndpointPairs(devFileObj parser.DevfileObj, debug bool) ([]PortPair, map[string]int, error) {
+	containers, err := devFileObj.Data.GetComponents(common.DevfileOptions{
+		ComponentOptions: common.ComponentOptions{ComponentType: v1alpha2.ContainerComponentType},
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var pairs []PortPair
+	requested := map[string]int{}
+	for _, container := range containers {
+		if container.ComponentUnion.Container == nil {
+			// this is not a container component; continue prevents panic when accessing Endpoints field
+			continue
+		}
+		for _, e := range container.Container.Endpoints {
+			if e.Exposure == v1alpha2.NoneEndpointExposure {
+				continue
+			}
+			pairs = append(pairs, PortPair{
+				ContainerName: container.Name,
+				EndpointName:  e.Name,
+				ContainerPort: e.TargetPort,
+			})
+			if e.Attributes.Exists(localPortAttribute) {
+				var attrErr error
+				port := e.Attributes.GetNumber(localPortAttribute, &attrErr)
+				if attrErr != nil {
+					return nil, nil, fmt.Errorf("invalid %s attribute for endpoint %q: %w", localPortAttribute, e.Name, attrErr)
+				}
+				requested[e.Name] = int(port)
+			}
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].ContainerName != pairs[j].ContainerName {
+			return pairs[i].ContainerName < pairs[j].ContainerName
+		}
+		if pairs[i].ContainerPort != pairs[j].ContainerPort {
+			return pairs[i].ContainerPort < pairs[j].ContainerPort
+		}
+		return pairs[i].EndpointName < pairs[j].EndpointName
+	})
+
+	if debug && !hasEndpoint(pairs, debugEndpointName) {
+		containerName, debugPort, err := getDebugPort(containers)
+		if err != nil {
+			return nil, nil, err
+		}
+		pairs = append(pairs, PortPair{
+			ContainerName: containerName,
+			EndpointName:  debugEndpointName,
+			ContainerPort: debugPort,
+		})
+	}
+	return pairs, requested, nil
+}
+
+func hasEndpoint(pairs []PortPair, name string) bool {
+	for _, pair := range pairs {
+		if pair.EndpointName == name {
+			return true
+		}
+	}
+	return false
+}
+
+// getDebugPort returns the debug port of a container: the one of the "debug" endpoint,
+// or the one defined by the DEBUG_PORT env var, of the first container defining one of them
+func getDebugPort(containers []v1alpha2.Component) (string, int, error) {
+	for _, container := range containers {
+		if container.ComponentUnion.Container == nil {
+			continue
+		}
+		for _, e := range container.Container.Endpoints {
+			if e.Name == debugEndpointName {
+				return container.Name, e.TargetPort, nil
+			}
+		}
+	}
+	for _, container := range containers {
+		if container.ComponentUnion.Container == nil {
+			continue
+		}
+		for _, env := range container.Container.Env {
+			if env.Name == debugPortEnv {
+				debugPort, err := strconv.Atoi(env.Value)
+				if err != nil {
+					return "", 0, fmt.Errorf("invalid value %q for %s in container %q: %w", env.Value, debugPortEnv, container.Name, err)
+				}
+				return container.Name, debugPort, nil
+			}
+		}
+	}
+	return "", 0, fmt.Errorf("no %q endpoint or %s env var found in devfile containers", debugEndpointName, debugPortEnv)
+}
+
+func isPortFree(port int) bool {
+	address := fmt.Sprintf("localhost:%d", port)
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return false
+	}
+	_ = listener.Addr().(*net.TCPAddr).Port
+	err = listener.Close()
+	return err == nil
+}
@@ -0,0 +1,302 @@
+package libdevfile
+
+import (
+	"net"
+	"testing"
+
+	"github.com/devfile/api/v2/pkg/apis/workspaces/v1alpha2"
+	"github.com/devfile/api/v2/pkg/attributes"
+)
+
+func containerComponent(name string, endpoints []v1alpha2.Endpoint, env ...v1alpha2.EnvVar) v1alpha2.Component {
+	return v1alpha2.Component{
+		Name: name,
+		ComponentUnion: v1alpha2.ComponentUnion{
+			Container: &v1alpha2.ContainerComponent{
+				Container: v1alpha2.Container{
+					Image: "image",
+					Env:   env,
+				},
+				Endpoints: endpoints,
+			},
+		},
+	}
+}
+
+func endpoint(name string, targetPort int, localPort int) v1alpha2.Endpoint {
+	e := v1alpha2.Endpoint{
+		Name:       name,
+		TargetPort: targetPort,
+	}
+	if localPort != 0 {
+		e.Attributes = attributes.Attributes{}.PutInteger(localPortAttribute, localPort)
+	}
+	return e
+}
+
+// listen returns a listener on a free local port, and the port
+func listen(t *testing.T) (net.Listener, int) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return listener, listener.Addr().(*net.TCPAddr).Port
+}
+
+// freePort returns a local port free at the time it is returned
+func freePort(t *testing.T) int {
+	t.Helper()
+	listener, port := listen(t)
+	if err := listener.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return port
+}
+
+func TestGetPortPairs(t *testing.T) {
+	previousPort := freePort(t)
+	busy, busyPort := listen(t)
+	defer busy.Close()
+
+	tests := []struct {
+		name       string
+		components []v1alpha2.Component
+		localPorts LocalPorts
+		debug      bool
+		// want are the expected local ports, indexed by endpoint name, zero for an allocated port
+		want    map[string]int
+		wantErr bool
+	}{
+		{
+			name: "pinned port takes precedence over attribute and previous port",
+			components: []v1alpha2.Component{
+				containerComponent("runtime", []v1alpha2.Endpoint{endpoint("http", 8080, 20080)}),
+			},
+			localPorts: LocalPorts{
+				Pinned:   map[string]int{"http": 20000},
+				Previous: map[string]int{"http": previousPort},
+			},
+			want: map[string]int{"http": 20000},
+		},
+		{
+			name: "attribute takes precedence over previous port",
+			components: []v1alpha2.Component{
+				containerComponent("runtime", []v1alpha2.Endpoint{endpoint("http", 8080, 20080)}),
+			},
+			localPorts: LocalPorts{
+				Previous: map[string]int{"http": previousPort},
+			},
+			want: map[string]int{"http": 20080},
+		},
+		{
+			name: "previous port used when free",
+			components: []v1alpha2.Component{
+				containerComponent("runtime", []v1alpha2.Endpoint{endpoint("http", 8080, 0)}),
+			},
+			localPorts: LocalPorts{
+				Previous: map[string]int{"http": previousPort},
+			},
+			want: map[string]int{"http": previousPort},
+		},
+		{
+			name: "previous port not used when busy",
+			components: []v1alpha2.Component{
+				containerComponent("runtime", []v1alpha2.Endpoint{endpoint("http", 8080, 0)}),
+			},
+			localPorts: LocalPorts{
+				Previous: map[string]int{"http": busyPort},
+			},
+			want: map[string]int{"http": 0},
+		},
+		{
+			name: "pinned port used even when busy",
+			components: []v1alpha2.Component{
+				containerComponent("runtime", []v1alpha2.Endpoint{endpoint("http", 8080, 0)}),
+			},
+			localPorts: LocalPorts{
+				Pinned: map[string]int{"http": busyPort},
+			},
+			want: map[string]int{"http": busyPort},
+		},
+		{
+			name: "allocated ports",
+			components: []v1alpha2.Component{
+				containerComponent("runtime", []v1alpha2.Endpoint{endpoint("http", 8080, 0), endpoint("admin", 9090, 0)}),
+			},
+			want: map[string]int{"http": 0, "admin": 0},
+		},
+		{
+			name: "same port pinned for two endpoints",
+			components: []v1alpha2.Component{
+				containerComponent("runtime", []v1alpha2.Endpoint{endpoint("http", 8080, 0), endpoint("admin", 9090, 0)}),
+			},
+			localPorts: LocalPorts{
+				Pinned: map[string]int{"http": 20000, "admin": 20000},
+			},
+			wantErr: true,
+		},
+		{
+			name: "port pinned and requested by attribute for two endpoints",
+			components: []v1alpha2.Component{
+				containerComponent("runtime", []v1alpha2.Endpoint{endpoint("http", 8080, 0), endpoint("admin", 9090, 20000)}),
+			},
+			localPorts: LocalPorts{
+				Pinned: map[string]int{"http": 20000},
+			},
+			wantErr: true,
+		},
+		{
+			name: "debug port from env var",
+			components: []v1alpha2.Component{
+				containerComponent("runtime", []v1alpha2.Endpoint{endpoint("http", 8080, 20080)}, v1alpha2.EnvVar{Name: debugPortEnv, Value: "5005"}),
+			},
+			debug: true,
+			want:  map[string]int{"http": 20080, debugEndpointName: 0},
+		},
+		{
+			name: "debug without debug port",
+			components: []v1alpha2.Component{
+				containerComponent("runtime", []v1alpha2.Endpoint{endpoint("http", 8080, 0)}),
+			},
+			debug:   true,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			devfileObj := newDevfileObj(t, tt.components, nil)
+			pairs, err := GetPortPairs(devfileObj, tt.localPorts, tt.debug)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetPortPairs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if len(pairs) != len(tt.want) {
+				t.Fatalf("GetPortPairs() = %v, want %d pairs", pairs, len(tt.want))
+			}
+			used := map[int]bool{}
+			for _, pair := range pairs {
+				want, ok := tt.want[pair.EndpointName]
+				if !ok {
+					t.Fatalf("unexpected endpoint %q", pair.EndpointName)
+				}
+				if used[pair.LocalPort] {
+					t.Errorf("local port %d assigned to several endpoints", pair.LocalPort)
+				}
+				used[pair.LocalPort] = true
+				switch {
+				case want != 0 && pair.LocalPort != want:
+					t.Errorf("local port of %q = %d, want %d", pair.EndpointName, pair.LocalPort, want)
+				case want == 0 && pair.EndpointName == debugEndpointName && pair.LocalPort < debugLocalPort:
+					t.Errorf("local port of %q = %d, want a port starting at %d", pair.EndpointName, pair.LocalPort, debugLocalPort)
+				case want == 0 && pair.EndpointName != debugEndpointName && pair.LocalPort < firstLocalPort:
+					t.Errorf("local port of %q = %d, want a port starting at %d", pair.EndpointName, pair.LocalPort, firstLocalPort)
+				}
+			}
+		})
+	}
+}
+
+func TestGetPortPairsOrder(t *testing.T) {
+	devfileObj := newDevfileObj(t, []v1alpha2.Component{
+		containerComponent("b", []v1alpha2.Endpoint{endpoint("b-http", 8080, 0)}),
+		containerComponent("a", []v1alpha2.Endpoint{endpoint("a-admin", 9090, 0), endpoint("a-http", 8080, 0)}),
+	}, nil)
+	pairs, err := GetPortPairs(devfileObj, LocalPorts{}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, pair := range pairs {
+		names = append(names, pair.EndpointName)
+	}
+	want := []string{"a-http", "a-admin", "b-http"}
+	if len(names) != len(want) {
+		t.Fatalf("endpoints = %v, want %v", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Fatalf("endpoints = %v, want %v", names, want)
+		}
+		if i > 0 && pairs[i].LocalPort <= pairs[i-1].LocalPort {
+			t.Errorf("local ports %v not assigned in the order of the endpoints", pairs)
+		}
+	}
+}
+
+func TestGetDebugPort(t *testing.T) {
+	debugEnv := v1alpha2.EnvVar{Name: debugPortEnv, Value: "9229"}
+	tests := []struct {
+		name          string
+		containers    []v1alpha2.Component
+		wantContainer string
+		wantPort      int
+		wantErr       bool
+	}{
+		{
+			name: "debug endpoint takes precedence over env var",
+			containers: []v1alpha2.Component{
+				containerComponent("runtime", []v1alpha2.Endpoint{endpoint("http", 8080, 0), endpoint(debugEndpointName, 5005, 0)}, debugEnv),
+			},
+			wantContainer: "runtime",
+			wantPort:      5005,
+		},
+		{
+			name: "debug endpoint of another container takes precedence over env var",
+			containers: []v1alpha2.Component{
+				containerComponent("runtime", nil, debugEnv),
+				containerComponent("tools", []v1alpha2.Endpoint{endpoint(debugEndpointName, 5005, 0)}),
+			},
+			wantContainer: "tools",
+			wantPort:      5005,
+		},
+		{
+			name: "env var",
+			containers: []v1alpha2.Component{
+				containerComponent("runtime", []v1alpha2.Endpoint{endpoint("http", 8080, 0)}),
+				containerComponent("tools", nil, debugEnv),
+			},
+			wantContainer: "tools",
+			wantPort:      9229,
+		},
+		{
+			name: "invalid env var",
+			containers: []v1alpha2.Component{
+				containerComponent("runtime", nil, v1alpha2.EnvVar{Name: debugPortEnv, Value: "debug"}),
+			},
+			wantErr: true,
+		},
+		{
+			name: "no debug port",
+			containers: []v1alpha2.Component{
+				containerComponent("runtime", []v1alpha2.Endpoint{endpoint("http", 8080, 0)}),
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			container, port, err := getDebugPort(tt.containers)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getDebugPort() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if container != tt.wantContainer || port != tt.wantPort {
+				t.Errorf("getDebugPort() = %q, %d, want %q, %d", container, port, tt.wantContainer, tt.wantPort)
+			}
+		})
+	}
+}
+
+func TestCheckPinnedPorts(t *testing.T) {
+	devfileObj := newDevfileObj(t, []v1alpha2.Component{
+		containerComponent("runtime", []v1alpha2.Endpoint{endpoint("http", 8080, 0)}),
+	}, nil)
+	if err := CheckPinnedPorts(devfileObj, map[string]int{"http": 20000}, false); err != nil {
+		t.Errorf("CheckPinnedPorts() error = %v for a known endpoint", err)
+	}
+	if err := CheckPinnedPorts(devfileObj, map[string]int{"htpp": 20000}, false); err == nil {
+		t.Errorf("CheckPinnedPorts() no error for an unknown endpoint")
+	}
+}
@@ -0,0 +1,28 @@
+package libdevfile
+
+import (
+	"github.com/devfile/api/v2/pkg/apis/workspaces/v1alpha2"
+	"github.com/devfile/api/v2/pkg/validation/variables"
+)
+
+// GetK8sManifestWithVariablesSubstituted returns the manifest of a Kubernetes component
+// with the {{variable}} references replaced by their values.
+// The invalid variable references are returned as warnings
+func GetK8sManifestWithVariablesSubstituted(manifest string, vars map[string]string) (string, []string) {
+	components := []v1alpha2.Component{
+		{
+			Name: "manifest",
+			ComponentUnion: v1alpha2.ComponentUnion{
+				Kubernetes: &v1alpha2.KubernetesComponent{
+					K8sLikeComponent: v1alpha2.K8sLikeComponent{
+						K8sLikeComponentLocation: v1alpha2.K8sLikeComponentLocation{
+							Inlined: manifest,
+						},
+					},
+				},
+			},
+		},
+	}
+	warnings := variables.ValidateAndReplaceForComponents(vars, components)
+	return components[0].Kubernetes.Inlined, warnings["manifest"]
+}
@@ -0,0 +1,46 @@
+package libdevfile
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGetK8sManifestWithVariablesSubstituted(t *testing.T) {
+	tests := []struct {
+		name         string
+		manifest     string
+		vars         map[string]string
+		want         string
+		wantWarnings []string
+	}{
+		{
+			name:     "no variable reference",
+			manifest: "name: config",
+			vars:     map[string]string{"name": "other"},
+			want:     "name: config",
+		},
+		{
+			name:     "variables substituted",
+			manifest: "name: {{name}}\nimage: {{image}}:latest",
+			vars:     map[string]string{"name": "config", "image": "quay.io/image"},
+			want:     "name: config\nimage: quay.io/image:latest",
+		},
+		{
+			name:         "undefined variable",
+			manifest:     "name: {{name}}",
+			want:         "name: {{name}}",
+			wantWarnings: []string{"name"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, warnings := GetK8sManifestWithVariablesSubstituted(tt.manifest, tt.vars)
+			if got != tt.want {
+				t.Errorf("GetK8sManifestWithVariablesSubstituted() = %q, want %q", got, tt.want)
+			}
+			if !reflect.DeepEqual(warnings, tt.wantWarnings) {
+				t.Errorf("GetK8sManifestWithVariablesSubstituted() warnings = %v, want %v", warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
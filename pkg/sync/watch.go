@@ -2,9 +2,11 @@ package sync
 
 import (
 	"context"
+	"fmt"
 	"path/filepath"
 	"time"
 
+	"github.com/feloy/ododev/pkg/devfile"
 	"github.com/feloy/ododev/pkg/filesystem"
 
 	corev1 "k8s.io/api/core/v1"
@@ -14,13 +16,21 @@ import (
 	gitignore "github.com/sabhiram/go-gitignore"
 )
 
+const (
+	// syncDelay is the delay without new changes after which the sources are synchronized
+	syncDelay = 100 * time.Millisecond
+	// syncRetryDelay is the delay after which the synchronization of the sources is retried, after a failure
+	syncRetryDelay = 5 * time.Second
+)
+
 func Watch(
 	ctx context.Context,
 	devfilePath string,
 	wd string,
 	ignoreMatcher *gitignore.GitIgnore,
+	odoDir string,
 	statusWatcher <-chan watch.Event,
-	updatedStatus func(status string),
+	updatedStatus func(status devfile.StatusContent),
 	modifiedDevfile func() error,
 	modifiedSources func(deleted []string, modified []string) error,
 ) error {
@@ -65,23 +75,39 @@ func Watch(
 			if matched := ignoreMatcher.MatchesPath(rel); matched {
 				continue
 			}
+			// the .git directory and the local files of ododev are never synchronized, as in filesystem.Archive
+			if filesystem.IsExcluded(rel, odoDir) {
+				continue
+			}
+			// the last event of a file decides if it is modified or deleted
 			switch event {
 			case notify.InCloseWrite:
+				delete(deleted, rel)
 				modified[rel] = struct{}{}
 			case notify.InDelete:
+				delete(modified, rel)
 				deleted[rel] = struct{}{}
 			}
-			timer.Reset(100 * time.Millisecond)
+			timer.Reset(syncDelay)
 
 		case <-timer.C:
-			modifiedSources(mapKeysToSlice(deleted), mapKeysToSlice(modified))
+			err = modifiedSources(mapKeysToSlice(deleted), mapKeysToSlice(modified))
+			if err != nil {
+				// the changes are kept, to be synchronized again with the next changes, or after a delay
+				fmt.Printf("error synchronizing the sources, retrying in %s: %s\n", syncRetryDelay, err)
+				timer.Reset(syncRetryDelay)
+				continue
+			}
 			deleted = map[string]struct{}{}
 			modified = map[string]struct{}{}
 
 		case event := <-statusWatcher:
 			switch obj := event.Object.(type) {
 			case *corev1.ConfigMap:
-				status := obj.Data["status"]
+				status, err := devfile.StatusFromConfigMap(obj)
+				if err != nil {
+					return err
+				}
 				updatedStatus(status)
 			}
 